@@ -0,0 +1,70 @@
+package aero
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+)
+
+// APIKeyAuth authenticates requests via API keys sent in a header
+// or a query parameter. Keys maps each API key to its subject.
+type APIKeyAuth struct {
+	Header string
+	Query  string
+	Keys   map[string]string
+}
+
+// Middleware returns a middleware that responds with 401 Unauthorized
+// unless the request contains a valid API key.
+// The subject of the key is stored as the principal of the request.
+func (auth *APIKeyAuth) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context) error {
+			key := ""
+
+			if auth.Header != "" {
+				key = ctx.Request().Header(auth.Header)
+			}
+
+			if key == "" && auth.Query != "" {
+				key = ctx.Query(auth.Query)
+			}
+
+			subject, ok := auth.lookup(key)
+
+			if !ok {
+				return ctx.Error(http.StatusUnauthorized)
+			}
+
+			ctx.SetPrincipal(&Principal{
+				Subject: subject,
+				Scheme:  AuthAPIKey,
+			})
+
+			return next(ctx)
+		}
+	}
+}
+
+// lookup finds the subject of the key in constant time
+// with regard to the contents of the key.
+func (auth *APIKeyAuth) lookup(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	keyHash := sha256.Sum256([]byte(key))
+	subject := ""
+	found := false
+
+	for expectedKey, expectedSubject := range auth.Keys {
+		expectedHash := sha256.Sum256([]byte(expectedKey))
+
+		if subtle.ConstantTimeCompare(keyHash[:], expectedHash[:]) == 1 {
+			subject = expectedSubject
+			found = true
+		}
+	}
+
+	return subject, found
+}
@@ -0,0 +1,43 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	app := aero.New()
+	auth := &aero.APIKeyAuth{
+		Header: "X-API-Key",
+		Query:  "api_key",
+		Keys: map[string]string{
+			"key-1": "service-1",
+		},
+	}
+
+	app.Get("/", aero.Handler(func(ctx aero.Context) error {
+		principal := ctx.Principal()
+		return ctx.Text(principal.Scheme + ":" + principal.Subject)
+	}).Bind(auth.Middleware()))
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("X-API-Key", "key-1")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Body.String(), "apikey:service-1")
+
+	response = test(app, "/?api_key=key-1")
+	assert.Equal(t, response.Code, http.StatusOK)
+
+	response = test(app, "/?api_key=key-2")
+	assert.Equal(t, response.Code, http.StatusUnauthorized)
+
+	response = test(app, "/")
+	assert.Equal(t, response.Code, http.StatusUnauthorized)
+}
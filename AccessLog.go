@@ -0,0 +1,220 @@
+package aero
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Access log formats.
+const (
+	AccessLogCommon   = "common"
+	AccessLogCombined = "combined"
+	AccessLogJSON     = "json"
+)
+
+// AccessLog writes a line for every request to the writer.
+// Format is one of AccessLogCommon, AccessLogCombined or AccessLogJSON (default).
+// SampleRate between 0 and 1 logs only a fraction of the successful requests,
+// server errors with a status of 500 or higher are always logged.
+// Requests to the route patterns in Exclude are never logged.
+type AccessLog struct {
+	Writer     io.Writer
+	Format     string
+	SampleRate float64
+	Exclude    []string
+
+	mutex sync.Mutex
+}
+
+// AccessLogEntry contains the data of a single request.
+type AccessLogEntry struct {
+	Time      time.Time     `json:"time"`
+	Method    string        `json:"method"`
+	Route     string        `json:"route"`
+	Path      string        `json:"path"`
+	Query     string        `json:"query,omitempty"`
+	Protocol  string        `json:"protocol"`
+	Status    int           `json:"status"`
+	Bytes     int64         `json:"bytes"`
+	Duration  time.Duration `json:"-"`
+	IP        string        `json:"ip"`
+	User      string        `json:"user,omitempty"`
+	UserAgent string        `json:"userAgent,omitempty"`
+	Referrer  string        `json:"referrer,omitempty"`
+	RequestID string        `json:"requestId,omitempty"`
+}
+
+// NewAccessLog creates a new access log using the given writer and format.
+func NewAccessLog(writer io.Writer, format string) *AccessLog {
+	return &AccessLog{
+		Writer: writer,
+		Format: format,
+	}
+}
+
+// AccessLog writes the access log for every request handled by the app,
+// including requests that didn't match a route, CORS preflight requests,
+// HTTPS redirects and panics. Use this instead of log.Middleware()
+// to log all requests.
+func (app *Application) AccessLog(log *AccessLog) {
+	app.accessLog = log
+}
+
+// Middleware returns a middleware that logs the requests of the routes it is bound to.
+// Requests that don't reach a route are not logged, use app.AccessLog for those.
+func (log *AccessLog) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context) error {
+			start := time.Now()
+			panicking := true
+
+			// Requests whose handler panics are logged as well
+			defer func() {
+				if !panicking {
+					return
+				}
+
+				status := ctx.Status()
+
+				if !ctx.Response().HeadersSent() {
+					status = http.StatusInternalServerError
+				}
+
+				log.request(ctx, start, status)
+			}()
+
+			err := next(ctx)
+			panicking = false
+			log.request(ctx, start, ctx.Status())
+			return err
+		}
+	}
+}
+
+// request writes the entry for a finished request
+// unless the route is excluded or the request is not sampled.
+func (log *AccessLog) request(ctx Context, start time.Time, status int) {
+	route := ctx.Route()
+
+	for _, excluded := range log.Exclude {
+		if route == excluded {
+			return
+		}
+	}
+
+	if status < http.StatusInternalServerError && log.SampleRate > 0 && log.SampleRate < 1 && rand.Float64() >= log.SampleRate {
+		return
+	}
+
+	request := ctx.Request().Internal()
+
+	entry := &AccessLogEntry{
+		Time:      start,
+		Method:    request.Method,
+		Route:     route,
+		Path:      request.URL.Path,
+		Query:     request.URL.RawQuery,
+		Protocol:  request.Proto,
+		Status:    status,
+		Bytes:     ctx.Response().Size(),
+		Duration:  time.Since(start),
+		IP:        ctx.IP(),
+		UserAgent: request.UserAgent(),
+		Referrer:  request.Referer(),
+		RequestID: ctx.RequestID(),
+	}
+
+	if principal := ctx.Principal(); principal != nil {
+		entry.User = principal.Subject
+	}
+
+	log.Write(entry)
+}
+
+// Write formats the entry and writes it as a single line.
+func (log *AccessLog) Write(entry *AccessLogEntry) {
+	buffer := bytes.Buffer{}
+
+	switch log.Format {
+	case AccessLogCommon, AccessLogCombined:
+		buffer.WriteString(entry.IP)
+		buffer.WriteString(" - ")
+		buffer.WriteString(commonLogField(strings.Map(replaceSpace, entry.User)))
+		buffer.WriteString(" [")
+		buffer.WriteString(entry.Time.Format("02/Jan/2006:15:04:05 -0700"))
+		buffer.WriteString("] ")
+
+		requestLine := entry.Method + " " + entry.Path
+
+		if entry.Query != "" {
+			requestLine += "?" + entry.Query
+		}
+
+		buffer.WriteString(strconv.Quote(requestLine + " " + entry.Protocol))
+		buffer.WriteByte(' ')
+		buffer.WriteString(strconv.Itoa(entry.Status))
+		buffer.WriteByte(' ')
+
+		if entry.Bytes == 0 {
+			buffer.WriteByte('-')
+		} else {
+			buffer.WriteString(strconv.FormatInt(entry.Bytes, 10))
+		}
+
+		if log.Format == AccessLogCombined {
+			buffer.WriteByte(' ')
+			buffer.WriteString(strconv.Quote(commonLogField(entry.Referrer)))
+			buffer.WriteByte(' ')
+			buffer.WriteString(strconv.Quote(commonLogField(entry.UserAgent)))
+		}
+
+		buffer.WriteByte('\n')
+
+	default:
+		data, err := json.Marshal(struct {
+			*AccessLogEntry
+			Duration float64 `json:"durationMs"`
+		}{
+			AccessLogEntry: entry,
+			Duration:       float64(entry.Duration) / float64(time.Millisecond),
+		})
+
+		if err != nil {
+			return
+		}
+
+		buffer.Write(data)
+		buffer.WriteByte('\n')
+	}
+
+	log.mutex.Lock()
+	_, _ = log.Writer.Write(buffer.Bytes())
+	log.mutex.Unlock()
+}
+
+// commonLogField returns "-" for empty values as defined in the Common Log Format.
+func commonLogField(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+// replaceSpace replaces whitespace and control characters
+// in unquoted fields of the Common Log Format.
+func replaceSpace(r rune) rune {
+	if unicode.IsSpace(r) || unicode.IsControl(r) {
+		return '_'
+	}
+
+	return r
+}
@@ -0,0 +1,165 @@
+package aero_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestAccessLogJSON(t *testing.T) {
+	app := aero.New()
+	output := &bytes.Buffer{}
+	log := aero.NewAccessLog(output, aero.AccessLogJSON)
+	log.Exclude = []string{"/healthz"}
+
+	app.Use(aero.RequestID(), log.Middleware())
+
+	app.Get("/user/:id", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	app.Get("/healthz", func(ctx aero.Context) error {
+		return ctx.Text("OK")
+	})
+
+	app.BindMiddleware()
+
+	request := httptest.NewRequest("GET", "/user/42?tab=posts", nil)
+	request.Header.Set("User-Agent", "Test")
+	request.Header.Set("X-Request-Id", "abc-123")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	test(app, "/healthz")
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Equal(t, len(lines), 1)
+
+	entry := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, entry["method"], "GET")
+	assert.Equal(t, entry["route"], "/user/:id")
+	assert.Equal(t, entry["path"], "/user/42")
+	assert.Equal(t, entry["query"], "tab=posts")
+	assert.Equal(t, entry["status"], 200.0)
+	assert.Equal(t, entry["bytes"], float64(len(helloWorld)))
+	assert.Equal(t, entry["ip"], "192.0.2.1")
+	assert.Equal(t, entry["userAgent"], "Test")
+	assert.Equal(t, entry["requestId"], "abc-123")
+	assert.NotNil(t, entry["durationMs"])
+	assert.NotNil(t, entry["time"])
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	app := aero.New()
+	output := &bytes.Buffer{}
+	log := aero.NewAccessLog(output, aero.AccessLogCombined)
+	auth := &aero.BasicAuth{Users: map[string]string{"john doe": "secret"}}
+
+	app.Use(log.Middleware(), auth.Middleware())
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.File("testdata/config.json")
+	})
+
+	app.BindMiddleware()
+
+	request := httptest.NewRequest("GET", "/?q=\"quoted\"", nil)
+	request.Header.Set("User-Agent", "Mozilla/5.0")
+	request.SetBasicAuth("john doe", "secret")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusOK)
+
+	line := output.String()
+	pattern := `^192\.0\.2\.1 - john_doe \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /\?q="quoted" HTTP/1\.1" 200 \d+ "-" "Mozilla/5\.0"\n$`
+	assert.True(t, regexp.MustCompile(pattern).MatchString(strings.Replace(line, `\"`, `"`, -1)))
+	assert.False(t, strings.Contains(line, " 200 - "))
+
+	// Failed authentication
+	output.Reset()
+	response = test(app, "/")
+	assert.Equal(t, response.Code, http.StatusUnauthorized)
+	assert.Contains(t, output.String(), `" 401 `)
+	assert.Contains(t, output.String(), ` - - [`)
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	app := aero.New()
+	output := &bytes.Buffer{}
+	log := aero.NewAccessLog(output, aero.AccessLogCommon)
+	log.SampleRate = 0.000001
+
+	app.Use(log.Middleware())
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	app.Get("/error", func(ctx aero.Context) error {
+		return ctx.Error(http.StatusInternalServerError)
+	})
+
+	app.BindMiddleware()
+
+	for i := 0; i < 10; i++ {
+		test(app, "/")
+	}
+
+	assert.Equal(t, output.String(), "")
+
+	// Server errors are always logged
+	test(app, "/error")
+	assert.Contains(t, output.String(), `"GET /error HTTP/1.1" 500 21`)
+}
+
+func TestAccessLogPanic(t *testing.T) {
+	app := aero.New()
+	output := &bytes.Buffer{}
+	log := aero.NewAccessLog(output, aero.AccessLogCommon)
+
+	app.Use(log.Middleware())
+
+	app.Get("/panic", func(ctx aero.Context) error {
+		panic("Something went wrong")
+	})
+
+	app.BindMiddleware()
+	response := test(app, "/panic")
+
+	assert.Equal(t, response.Code, http.StatusInternalServerError)
+	assert.Contains(t, output.String(), `"GET /panic HTTP/1.1" 500 `)
+}
+
+func TestApplicationAccessLog(t *testing.T) {
+	app := aero.New()
+	output := &bytes.Buffer{}
+	log := aero.NewAccessLog(output, aero.AccessLogCommon)
+	app.AccessLog(log)
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	app.Get("/panic", func(ctx aero.Context) error {
+		panic("Something went wrong")
+	})
+
+	app.BindMiddleware()
+
+	test(app, "/")
+	test(app, "/404")
+	test(app, "/panic")
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Equal(t, len(lines), 3)
+	assert.Contains(t, lines[0], `"GET / HTTP/1.1" 200 `)
+	assert.Contains(t, lines[1], `"GET /404 HTTP/1.1" 404 `)
+	assert.Contains(t, lines[2], `"GET /panic HTTP/1.1" 500 `)
+}
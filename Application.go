@@ -3,6 +3,7 @@ package aero
 import (
 	"compress/gzip"
 	stdContext "context"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -14,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -27,28 +29,52 @@ import (
 
 // Application represents a single web service.
 type Application struct {
-	Config                *Configuration
-	Sessions              session.Manager
-	Security              ApplicationSecurity
-	Linters               []Linter
-	ContentSecurityPolicy *csp.ContentSecurityPolicy
-
-	router         Router
-	routeTests     map[string][]string
-	start          time.Time
-	rewrite        []func(RewriteContext)
-	middleware     []Middleware
-	pushConditions []func(Context) bool
-	onStart        []func()
-	onShutdown     []func()
-	onPush         []func(Context)
-	onError        []func(Context, error)
-	stop           chan os.Signal
-	pushOptions    http.PushOptions
-	contextPool    sync.Pool
-	gzipWriterPool sync.Pool
-	serversMutex   sync.Mutex
-	servers        [2]*http.Server
+	Config                          *Configuration
+	Sessions                        session.Manager
+	Security                        ApplicationSecurity
+	Linters                         []Linter
+	ContentSecurityPolicy           *csp.ContentSecurityPolicy
+	ContentSecurityPolicyReportOnly *csp.ContentSecurityPolicy
+	RateLimitBackend                RateLimitBackend
+	Tracer                          Tracer
+
+	router           Router
+	notFound         *route
+	methodNotAllowed *route
+	errorPages       map[int]func(Context, *HTTPError) error
+	metrics          *metrics
+	accessLog        *AccessLog
+	healthChecks     []*healthCheck
+	readiness        bool
+	shuttingDown     int32
+	routeTests       map[string][]string
+	start            time.Time
+	rewrite          []func(RewriteContext)
+	middleware       []Middleware
+	pushConditions   []func(Context) bool
+	onStart          []func()
+	onShutdown       []func(stdContext.Context)
+	onServerError    []func(error)
+	onPush           []func(Context)
+	onError          []func(Context, error)
+	onCSPViolation   []func(Context, *CSPViolation)
+	cspViolations    cspViolationFilter
+	cspReportPath    string
+	trustedProxies   *CIDRSet
+	stop             chan os.Signal
+	closing          chan struct{}
+	done             chan struct{}
+	pushOptions      http.PushOptions
+	contextPool      sync.Pool
+	gzipWriterPool   sync.Pool
+	startMutex       sync.Mutex
+	serversMutex     sync.Mutex
+	servers          []*http.Server
+	workers          []*preforkWorker
+	workerGroup      sync.WaitGroup
+	workersRestart   int32
+	listeners        []net.Listener
+	certificates     *certificateStore
 
 	routes struct {
 		GET []string
@@ -60,10 +86,18 @@ func New() *Application {
 	app := &Application{
 		start:                 time.Now(),
 		stop:                  make(chan os.Signal, 1),
+		closing:               make(chan struct{}),
+		done:                  make(chan struct{}),
 		routeTests:            make(map[string][]string),
+		trustedProxies:        loopbackCIDRs,
 		Config:                &Configuration{},
 		ContentSecurityPolicy: csp.New(),
 
+		// Default security headers
+		Security: ApplicationSecurity{
+			Headers: DefaultSecurityHeaders(),
+		},
+
 		// Default linters
 		Linters: []Linter{
 			performance.New(),
@@ -88,9 +122,19 @@ func New() *Application {
 
 	// Context pool
 	app.contextPool.New = func() interface{} {
-		return &context{
+		if app.metrics != nil {
+			atomic.AddUint64(&app.metrics.poolMisses, 1)
+		}
+
+		ctx := &context{
 			app: app,
+			request: request{
+				app: app,
+			},
 		}
+
+		ctx.response.writer.beforeSend = ctx.writeServerTiming
+		return ctx
 	}
 
 	// Push options describes the headers that are sent
@@ -109,12 +153,12 @@ func New() *Application {
 	// Default session store: Memory
 	app.Sessions.Store = memstore.New()
 
+	// Default rate limit backend: Memory
+	app.RateLimitBackend = NewMemoryRateLimitBackend()
+
 	// MIME types
 	initMIMETypes()
 
-	// Receive signals
-	signal.Notify(app.stop, os.Interrupt, syscall.SIGTERM)
-
 	return app
 }
 
@@ -152,18 +196,151 @@ func (app *Application) Router() *Router {
 	return &app.router
 }
 
-// Run starts your application.
+// Run starts your application and blocks until it receives
+// an interrupt or termination signal or Stop is called.
+// With graceful restarts enabled, SIGHUP and SIGUSR2 start a new
+// process that takes over the listeners before this one shuts down.
+// Otherwise, SIGHUP reloads the TLS certificates.
+// In prefork mode, the process supervises the configured number
+// of workers instead of serving requests itself. Restart signals
+// then replace the workers one by one and reload signals are
+// forwarded to them.
+// It panics if the listeners can't be created.
 func (app *Application) Run() {
-	app.BindMiddleware()
-	app.ListenAndServe()
+	switch app.Role() {
+	case RoleMaster:
+		err := app.runMaster()
 
-	for _, callback := range app.onStart {
-		callback()
+		if err != nil {
+			panic(err)
+		}
+
+		return
+
+	case RoleWorker:
+		go app.watchMaster()
+	}
+
+	// Receive signals
+	signal.Notify(app.stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(app.stop)
+
+	if app.Config.GracefulRestart && app.Role() == RoleStandalone {
+		signal.Notify(app.stop, restartSignals...)
+	} else if len(app.Security.files()) > 0 {
+		signal.Notify(app.stop, reloadSignals...)
+	}
+
+	err := app.Start(stdContext.Background())
+
+	if err != nil {
+		panic(err)
 	}
 
 	app.TestRoutes()
 	app.wait()
 	app.Shutdown()
+	<-app.done
+}
+
+// wait blocks until a termination signal arrives, a new process
+// took over after a restart signal or the app has been stopped.
+// Reload signals reload the certificates without restarting.
+func (app *Application) wait() {
+	for {
+		select {
+		case received := <-app.stop:
+			var err error
+
+			switch {
+			case app.Config.GracefulRestart && containsSignal(restartSignals, received):
+				err = app.restart()
+
+				if err == nil || err == errRestartInterrupted {
+					return
+				}
+
+			case containsSignal(reloadSignals, received):
+				err = app.ReloadCertificates()
+
+			default:
+				return
+			}
+
+			if err != nil {
+				app.serverError(err)
+			}
+
+		case <-app.done:
+			return
+		}
+	}
+}
+
+// containsSignal reports whether the signal is in the list.
+func containsSignal(signals []os.Signal, received os.Signal) bool {
+	for _, sig := range signals {
+		if received == sig {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Start binds the middleware, creates the listeners and serves
+// requests in the background. Unlike Run, it doesn't handle
+// process signals, so it's suitable for embedding and tests.
+// The context is only used while creating the listeners.
+func (app *Application) Start(ctx stdContext.Context) error {
+	// Concurrent calls must not both pass the check
+	app.startMutex.Lock()
+	defer app.startMutex.Unlock()
+
+	app.serversMutex.Lock()
+	running := app.listeners != nil
+	app.serversMutex.Unlock()
+
+	if running {
+		return errors.New("Application has already been started")
+	}
+
+	app.BindMiddleware()
+	err := app.serve(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, callback := range app.onStart {
+		callback()
+	}
+
+	// Let the previous process shut down after a restart
+	notifyReady()
+	return nil
+}
+
+// Stop gracefully shuts down the servers and returns when all
+// requests have finished or the context is done. It returns
+// the first error that occurred while shutting down the servers.
+func (app *Application) Stop(ctx stdContext.Context) error {
+	return app.shutdown(ctx)
+}
+
+// Addresses returns the addresses the servers are listening on.
+// This is useful to find out the actual port when binding to port 0.
+func (app *Application) Addresses() []net.Addr {
+	app.serversMutex.Lock()
+	defer app.serversMutex.Unlock()
+
+	addresses := make([]net.Addr, len(app.listeners))
+
+	for index, listener := range app.listeners {
+		addresses[index] = listener.Addr()
+	}
+
+	return addresses
 }
 
 // Use adds middleware to your middleware chain.
@@ -181,39 +358,235 @@ func (app *Application) Load() {
 	}
 
 	app.Config = config
+
+	if config.TrustedProxies != nil {
+		err = app.TrustProxies(config.TrustedProxies...)
+
+		if err != nil {
+			color.Red("Invalid trusted proxy in config.json: %v", err)
+		}
+	}
+}
+
+// TrustProxies replaces the list of trusted proxies with the given CIDR blocks
+// or IP addresses. Forwarding headers like X-Forwarded-For and Forwarded are
+// only respected when the request comes from a trusted proxy.
+// By default, only loopback addresses are trusted.
+// Calling it without arguments disables all forwarding headers.
+func (app *Application) TrustProxies(cidrs ...string) error {
+	trusted, err := NewCIDRSet(cidrs...)
+
+	if err != nil {
+		return err
+	}
+
+	app.trustedProxies = trusted
+	return nil
 }
 
 // ListenAndServe starts the server.
 // It guarantees that a TCP listener is listening on the ports defined in the config
-// when the function returns.
+// when the function returns. It panics if the listeners can't be created.
 func (app *Application) ListenAndServe() {
-	if app.Security.Key != "" && app.Security.Certificate != "" {
-		listener := app.listen(":" + strconv.Itoa(app.Config.Ports.HTTPS))
-		go app.serveHTTPS(listener)
-		fmt.Println("Server running on:", color.GreenString("https://localhost:"+strconv.Itoa(app.Config.Ports.HTTPS)))
+	err := app.serve(stdContext.Background())
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// serve creates the listeners and starts the servers.
+// If any listener can't be created, the others are closed.
+func (app *Application) serve(ctx stdContext.Context) error {
+	configs := app.listenerConfigurations()
+	listeners := make([]net.Listener, 0, len(configs))
+	var certificates *certificateStore
+
+	closeListeners := func() {
+		for _, listener := range listeners {
+			listener.Close()
+		}
+
+		releaseSystemdListeners(listeners)
+	}
+
+	for index := range configs {
+		config := &configs[index]
+
+		if config.TLS && certificates == nil {
+			files := app.Security.files()
+
+			if len(files) == 0 {
+				closeListeners()
+				return fmt.Errorf("TLS listener '%s': No certificates configured", config.Address)
+			}
+
+			// Certificates that can't be loaded don't stop the other listeners.
+			// They are loaded again as soon as the files change.
+			var err error
+			certificates, err = newCertificateStore(files)
+
+			if err != nil {
+				app.serverError(fmt.Errorf("TLS listener '%s': %v", config.Address, err))
+			}
+		}
+
+		// After a restart, the listeners are passed on by the previous process
+		listener, err := inheritedListener(index, len(configs))
+
+		if listener == nil && err == nil {
+			listener, err = config.listen(ctx, app.Role() == RoleWorker)
+		}
+
+		if err != nil {
+			closeListeners()
+			return err
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	servers := make([]*http.Server, len(listeners))
+
+	for index := range servers {
+		servers[index] = app.createServer()
+
+		if configs[index].TLS {
+			servers[index].TLSConfig.GetCertificate = certificates.GetCertificate
+		}
+	}
+
+	app.serversMutex.Lock()
+	app.listeners = listeners
+	app.servers = servers
+	app.certificates = certificates
+	app.serversMutex.Unlock()
+
+	if certificates != nil {
+		go certificates.watch(app)
+	}
+
+	for index, listener := range listeners {
+		config := &configs[index]
+		go app.serveListener(servers[index], listener, config.TLS)
+		fmt.Println("Server running on:", color.GreenString(config.url(listener)))
 	}
 
-	listener := app.listen(":" + strconv.Itoa(app.Config.Ports.HTTP))
-	go app.serveHTTP(listener)
-	fmt.Println("Server running on:", color.GreenString("http://localhost:"+strconv.Itoa(app.Config.Ports.HTTP)))
+	return nil
 }
 
-// wait will make the process wait until it is killed.
-func (app *Application) wait() {
-	<-app.stop
+// listenerConfigurations returns the configured listeners.
+// Without any, the listeners are derived from the ports.
+func (app *Application) listenerConfigurations() []ListenerConfiguration {
+	if len(app.Config.Listeners) > 0 {
+		return app.Config.Listeners
+	}
+
+	configs := []ListenerConfiguration{
+		{
+			Network: "tcp",
+			Address: ":" + strconv.Itoa(app.Config.Ports.HTTP),
+		},
+	}
+
+	if len(app.Security.files()) > 0 {
+		configs = append(configs, ListenerConfiguration{
+			Network: "tcp",
+			Address: ":" + strconv.Itoa(app.Config.Ports.HTTPS),
+			TLS:     true,
+		})
+	}
+
+	return configs
 }
 
 // Shutdown will gracefully shut down all servers.
+// Readiness checks fail immediately. After the configured delay,
+// event streams are closed and the servers stop accepting new
+// connections. In-flight requests have until the timeout to finish.
+// Finally, the OnShutdown callbacks receive the shutdown context.
+// Errors are reported to the OnServerError callbacks.
 func (app *Application) Shutdown() {
+	ctx := stdContext.Background()
+
+	if app.Config.Shutdown.Timeout.Duration > 0 {
+		var cancel stdContext.CancelFunc
+		ctx, cancel = stdContext.WithTimeout(ctx, app.Config.Shutdown.Timeout.Duration+app.shutdownDelay())
+		defer cancel()
+	}
+
+	err := app.shutdown(ctx)
+
+	if err != nil {
+		app.serverError(err)
+	}
+}
+
+// shutdown drains the servers until the context is done.
+// Concurrent calls wait for the first one to finish.
+func (app *Application) shutdown(ctx stdContext.Context) error {
+	// Fail readiness checks from now on
+	if !atomic.CompareAndSwapInt32(&app.shuttingDown, 0, 1) {
+		select {
+		case <-app.done:
+		case <-ctx.Done():
+		}
+
+		return nil
+	}
+
+	defer close(app.done)
+
 	app.serversMutex.Lock()
-	defer app.serversMutex.Unlock()
+	servers := app.servers
+	listeners := app.listeners
+	workers := app.workers
+	app.serversMutex.Unlock()
+
+	// Give load balancers time to notice the failed readiness checks.
+	// In prefork mode, the workers wait for the delay themselves.
+	if workers == nil && app.shutdownDelay() > 0 {
+		select {
+		case <-time.After(app.shutdownDelay()):
+		case <-ctx.Done():
+		}
+	}
+
+	// Event streams never finish on their own
+	close(app.closing)
+	app.stopWorkers(ctx, workers)
+
+	var firstErr error
+
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
 
-	shutdown(app.servers[0])
-	shutdown(app.servers[1])
+		err := server.Shutdown(ctx)
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	releaseSystemdListeners(listeners)
 
 	for _, callback := range app.onShutdown {
-		callback()
+		callback(ctx)
+	}
+
+	return firstErr
+}
+
+// shutdownDelay returns the time to wait before closing the listeners.
+// Without a readiness route there is nobody to notice the failing checks.
+func (app *Application) shutdownDelay() time.Duration {
+	if !app.readiness {
+		return 0
 	}
+
+	return app.Config.Shutdown.Delay.Duration
 }
 
 // OnStart registers a callback to be executed on server start.
@@ -223,9 +596,35 @@ func (app *Application) OnStart(callback func()) {
 
 // OnEnd registers a callback to be executed on server shutdown.
 func (app *Application) OnEnd(callback func()) {
+	app.OnShutdown(func(stdContext.Context) {
+		callback()
+	})
+}
+
+// OnShutdown registers a callback to be executed after the servers
+// have been shut down. The context expires at the shutdown deadline.
+func (app *Application) OnShutdown(callback func(stdContext.Context)) {
 	app.onShutdown = append(app.onShutdown, callback)
 }
 
+// OnServerError registers a callback to be executed
+// when an error occurs outside of a request, e.g. during shutdown.
+func (app *Application) OnServerError(callback func(error)) {
+	app.onServerError = append(app.onServerError, callback)
+}
+
+// serverError reports an error that doesn't belong to a request.
+func (app *Application) serverError(err error) {
+	if len(app.onServerError) == 0 {
+		color.Red(err.Error())
+		return
+	}
+
+	for _, callback := range app.onServerError {
+		callback(err)
+	}
+}
+
 // OnPush registers a callback to be executed when an HTTP/2 push happens.
 func (app *Application) OnPush(callback func(Context)) {
 	app.onPush = append(app.onPush, callback)
@@ -236,6 +635,13 @@ func (app *Application) OnError(callback func(Context, error)) {
 	app.onError = append(app.onError, callback)
 }
 
+// OnCSPViolation registers a callback to be executed when a client
+// reports a content security policy violation via the CSPReport route.
+// Identical reports are deduplicated before the callbacks are invoked.
+func (app *Application) OnCSPViolation(callback func(Context, *CSPViolation)) {
+	app.onCSPViolation = append(app.onCSPViolation, callback)
+}
+
 // AddPushCondition registers a callback that
 // needs to return true before an HTTP/2 push happens.
 func (app *Application) AddPushCondition(test func(Context) bool) {
@@ -255,38 +661,126 @@ func (app *Application) StartTime() time.Time {
 // NewContext returns a new context from the pool.
 func (app *Application) NewContext(req *http.Request, res http.ResponseWriter) *context {
 	ctx := app.contextPool.Get().(*context)
+
+	if app.metrics != nil {
+		atomic.AddUint64(&app.metrics.poolGets, 1)
+	}
+
 	ctx.status = http.StatusOK
 	ctx.request.inner = req
-	ctx.response.inner = res
+	ctx.response.writer.reset(res, time.Now())
+	ctx.response.inner = &ctx.response.writer
 	ctx.session = nil
+	ctx.principal = nil
+	ctx.trace = requestTrace{}
+	ctx.span = nil
+	ctx.rootSpan = nil
+	ctx.errorRendered = false
+	ctx.route = nil
 	ctx.paramCount = 0
 	ctx.modifierCount = 0
+	ctx.timingCount = 0
+	ctx.handlerStart = time.Time{}
 	return ctx
 }
 
 // ServeHTTP responds to the given request.
 func (app *Application) ServeHTTP(response http.ResponseWriter, request *http.Request) {
 	ctx := app.NewContext(request, response)
+	defer app.recoverPanic(ctx)
+
+	if app.metrics != nil {
+		app.metrics.begin()
+	}
+
+	if app.Tracer != nil {
+		app.startRequestSpan(ctx)
+	}
+
+	app.handle(ctx)
+	app.finish(ctx)
+}
+
+// handle routes the request to its handler and reports errors.
+func (app *Application) handle(ctx *context) {
+	request := ctx.request.inner
+	response := ctx.response.inner
+	app.applySecurityHeaders(ctx)
+
+	if app.Config.HTTPS.Redirect && app.redirectToHTTPS(ctx) {
+		return
+	}
+
+	// Answer CORS preflight requests without routing
+	if app.Config.CORS.Enabled() && app.Config.CORS.handle(request, response) {
+		return
+	}
 
 	for _, rewrite := range app.rewrite {
 		rewrite(ctx)
 	}
 
-	app.router.Lookup(request.Method, request.URL.Path, ctx)
+	lookupStart := time.Time{}
 
-	if ctx.handler == nil {
-		response.WriteHeader(http.StatusNotFound)
-		ctx.Close()
+	if app.Config.ServerTiming {
+		lookupStart = time.Now()
+	}
+
+	if app.Tracer != nil {
+		span, parent := ctx.startSpan("aero.router")
+		app.router.Lookup(request.Method, request.URL.Path, ctx)
+		span.SetAttribute("http.route", ctx.Route())
+		ctx.endSpan(span, parent)
+	} else {
+		app.router.Lookup(request.Method, request.URL.Path, ctx)
+	}
+
+	if app.Config.ServerTiming {
+		ctx.Timing("router", time.Since(lookupStart), "")
+	}
+
+	if ctx.route == nil && !app.fallback(ctx) {
 		return
 	}
 
-	err := ctx.handler(ctx)
+	if app.Tracer != nil {
+		span, parent := ctx.startSpan("aero.middleware")
+		defer ctx.endSpan(span, parent)
+	}
+
+	if app.Config.ServerTiming {
+		ctx.handlerStart = time.Now()
+	}
+
+	err := ctx.route.handler(ctx)
 
 	if err != nil {
+		var httpErr *HTTPError
+
+		// Render errors that were returned without calling ctx.Error
+		if !ctx.errorRendered && !ctx.response.HeadersSent() && errors.As(err, &httpErr) {
+			_ = ctx.renderError(httpErr)
+		}
+
 		for _, callback := range app.onError {
 			callback(ctx, err)
 		}
 	}
+}
+
+// finish records the request metrics and the access log and returns the context to the pool.
+func (app *Application) finish(ctx *context) {
+	if app.metrics != nil {
+		app.metrics.end(ctx)
+	}
+
+	if app.accessLog != nil {
+		app.accessLog.request(ctx, ctx.response.writer.start, ctx.Status())
+	}
+
+	if ctx.rootSpan != nil {
+		ctx.endRequestSpan()
+	}
 
 	ctx.Close()
 }
@@ -362,13 +856,44 @@ func (app *Application) acquireGZipWriter(response io.Writer) *gzip.Writer {
 
 // BindMiddleware applies the middleware to every router node.
 // This is called by `Run` automatically and should never be called
-// outside of tests.
+// outside of tests. With a tracer, the handlers are wrapped in spans,
+// therefore it needs to be set before.
 func (app *Application) BindMiddleware() {
+	// Routes that are already bound are skipped,
+	// so calling this again doesn't wrap them twice.
+	bind := func(current *route) *route {
+		if current.bound {
+			return current
+		}
+
+		handler := current.handler
+
+		if app.Tracer != nil {
+			handler = traceHandler(handler)
+		}
+
+		return &route{
+			pattern: current.pattern,
+			handler: handler.Bind(app.middleware...),
+			bound:   true,
+		}
+	}
+
 	app.router.Each(func(node *tree) {
+		// Nodes can share the same route, e.g. for trailing slashes,
+		// therefore every node gets its own copy.
 		if node.data != nil {
-			node.data = node.data.Bind(app.middleware...)
+			node.data = bind(node.data)
 		}
 	})
+
+	if app.notFound != nil {
+		app.notFound = bind(app.notFound)
+	}
+
+	if app.methodNotAllowed != nil {
+		app.methodNotAllowed = bind(app.methodNotAllowed)
+	}
 }
 
 // createServer creates an http server instance.
@@ -382,68 +907,22 @@ func (app *Application) createServer() *http.Server {
 	}
 }
 
-// listen returns a Listener for the given address.
-func (app *Application) listen(address string) Listener {
-	listener, err := net.Listen("tcp", address)
-
-	if err != nil {
-		panic(err)
-	}
-
-	return Listener{listener.(*net.TCPListener)}
-}
-
-// serveHTTP serves requests from the given listener.
-func (app *Application) serveHTTP(listener Listener) {
-	server := app.createServer()
-
-	app.serversMutex.Lock()
-	app.servers[0] = server
-	app.serversMutex.Unlock()
+// serveListener serves requests from the given listener.
+func (app *Application) serveListener(server *http.Server, listener net.Listener, useTLS bool) {
+	var err error
 
 	// This will block the calling goroutine until the server shuts down.
 	// The returned error is never nil and in case of a normal shutdown
 	// it will be `http.ErrServerClosed`.
-	err := server.Serve(listener)
-
-	if err != http.ErrServerClosed {
-		panic(err)
+	if useTLS {
+		// The certificates are provided by TLSConfig.GetCertificate
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		err = server.Serve(listener)
 	}
-}
-
-// serveHTTPS serves requests from the given listener.
-func (app *Application) serveHTTPS(listener Listener) {
-	server := app.createServer()
-
-	app.serversMutex.Lock()
-	app.servers[1] = server
-	app.serversMutex.Unlock()
-
-	// This will block the calling goroutine until the server shuts down.
-	// The returned error is never nil and in case of a normal shutdown
-	// it will be `http.ErrServerClosed`.
-	err := server.ServeTLS(listener, app.Security.Certificate, app.Security.Key)
 
 	if err != http.ErrServerClosed {
-		panic(err)
-	}
-}
-
-// shutdown will gracefully shut down the server.
-func shutdown(server *http.Server) {
-	if server == nil {
-		return
-	}
-
-	// Add a timeout to the server shutdown
-	ctx, cancel := stdContext.WithTimeout(stdContext.Background(), 250*time.Millisecond)
-	defer cancel()
-
-	// Shut down server
-	err := server.Shutdown(ctx)
-
-	if err != nil {
-		fmt.Println(err)
+		app.serverError(err)
 	}
 }
 
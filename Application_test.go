@@ -1,8 +1,11 @@
 package aero_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -170,6 +173,104 @@ func TestApplicationUnavailablePort(t *testing.T) {
 	app.ListenAndServe()
 }
 
+func TestApplicationStartStop(t *testing.T) {
+	apps := []*aero.Application{aero.New(), aero.New()}
+
+	for index, app := range apps {
+		message := fmt.Sprint(index)
+		app.Config.Ports.HTTP = 0
+
+		app.Get("/", func(ctx aero.Context) error {
+			return ctx.Text(message)
+		})
+
+		err := app.Start(context.Background())
+		assert.Nil(t, err)
+	}
+
+	for index, app := range apps {
+		addresses := app.Addresses()
+		assert.Equal(t, len(addresses), 1)
+		assert.NotEqual(t, addresses[0].(*net.TCPAddr).Port, 0)
+
+		response, err := http.Get("http://" + addresses[0].String() + "/")
+		assert.Nil(t, err)
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		assert.Equal(t, string(body), fmt.Sprint(index))
+	}
+
+	// Starting twice fails
+	assert.NotNil(t, apps[0].Start(context.Background()))
+
+	// Port is already in use
+	app := aero.New()
+	app.Config.Ports.HTTP = apps[0].Addresses()[0].(*net.TCPAddr).Port
+	assert.NotNil(t, app.Start(context.Background()))
+
+	for _, app := range apps {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		assert.Nil(t, app.Stop(ctx))
+		cancel()
+	}
+}
+
+func TestApplicationStartRetry(t *testing.T) {
+	blocker, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	app := aero.New()
+	app.Config.Ports.HTTP = blocker.Addr().(*net.TCPAddr).Port
+	calls := 0
+
+	app.Use(func(next aero.Handler) aero.Handler {
+		return func(ctx aero.Context) error {
+			calls++
+			return next(ctx)
+		}
+	})
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	// Port is already in use
+	assert.NotNil(t, app.Start(context.Background()))
+	blocker.Close()
+
+	// Concurrent calls can't both start the app
+	errs := make(chan error, 2)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			errs <- app.Start(context.Background())
+		}()
+	}
+
+	first, second := <-errs, <-errs
+	assert.True(t, (first == nil) != (second == nil))
+
+	// The middleware is only applied once
+	test(app, "/")
+	assert.Equal(t, calls, 1)
+
+	assert.Nil(t, app.Stop(context.Background()))
+}
+
+func TestApplicationRunStop(t *testing.T) {
+	app := aero.New()
+	app.Config.Ports.HTTP = 0
+
+	app.OnStart(func() {
+		go func() {
+			assert.Nil(t, app.Stop(context.Background()))
+		}()
+	})
+
+	app.Run()
+	assert.True(t, app.IsShuttingDown())
+}
+
 // test sends a request to the server and returns the response.
 func test(app *aero.Application, route string) *httptest.ResponseRecorder {
 	request := httptest.NewRequest("GET", route, nil)
@@ -194,3 +295,70 @@ func TestApplicationOnError(t *testing.T) {
 
 	test(app, "/")
 }
+
+func TestApplicationPanic(t *testing.T) {
+	app := aero.New()
+	var reported *aero.PanicError
+
+	app.Get("/user/:id", func(ctx aero.Context) error {
+		ctx.Response().SetHeader("ETag", "abc")
+		panic(errors.New("database is on fire"))
+	})
+
+	app.OnError(func(ctx aero.Context, err error) {
+		reported, _ = err.(*aero.PanicError)
+	})
+
+	response := test(app, "/user/42")
+
+	assert.Equal(t, response.Code, http.StatusInternalServerError)
+	assert.Equal(t, response.Body.String(), "Internal Server Error")
+	assert.Equal(t, response.Header().Get("ETag"), "")
+	assert.Equal(t, response.Header().Get("X-Content-Type-Options"), "nosniff")
+	assert.NotNil(t, reported)
+	assert.Equal(t, reported.Method, "GET")
+	assert.Equal(t, reported.Path, "/user/42")
+	assert.Equal(t, reported.Route, "/user/:id")
+	assert.Equal(t, reported.Unwrap().Error(), "database is on fire")
+	assert.Contains(t, string(reported.Stack), "TestApplicationPanic")
+
+	// The application keeps working after a panic
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(ctx.Route())
+	})
+
+	response = test(app, "/")
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Body.String(), "/")
+}
+
+func TestApplicationPanicDebug(t *testing.T) {
+	app := aero.New()
+	app.Config.Debug = true
+	app.OnError(func(ctx aero.Context, err error) {})
+
+	app.Get("/", func(ctx aero.Context) error {
+		panic("<script>")
+	})
+
+	response := test(app, "/")
+
+	assert.Equal(t, response.Code, http.StatusInternalServerError)
+	assert.Equal(t, response.Header().Get("Content-Type"), "text/html; charset=utf-8")
+	assert.Contains(t, response.Body.String(), "panic: &lt;script&gt;")
+	assert.Contains(t, response.Body.String(), "TestApplicationPanicDebug")
+}
+
+func TestApplicationPanicAbort(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/", func(ctx aero.Context) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		assert.Equal(t, recover(), http.ErrAbortHandler)
+	}()
+
+	test(app, "/")
+}
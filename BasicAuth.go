@@ -0,0 +1,69 @@
+package aero
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+)
+
+// BasicAuth authenticates requests via HTTP Basic authentication.
+// Users maps user names to passwords. If Check is set, it is used
+// instead of the Users map and is responsible for timing safety.
+type BasicAuth struct {
+	Realm string
+	Users map[string]string
+	Check func(user string, password string) bool
+}
+
+// Middleware returns a middleware that responds with 401 Unauthorized
+// unless the request contains valid credentials.
+// The user name is stored as the principal of the request.
+func (auth *BasicAuth) Middleware() Middleware {
+	realm := auth.Realm
+
+	if realm == "" {
+		realm = "Restricted"
+	}
+
+	challenge := `Basic realm="` + realm + `", charset="UTF-8"`
+
+	return func(next Handler) Handler {
+		return func(ctx Context) error {
+			user, password, ok := ctx.Request().Internal().BasicAuth()
+
+			if !ok || !auth.verify(user, password) {
+				ctx.Response().SetHeader(wwwAuthenticateHeader, challenge)
+				return ctx.Error(http.StatusUnauthorized)
+			}
+
+			ctx.SetPrincipal(&Principal{
+				Subject: user,
+				Scheme:  AuthBasic,
+			})
+
+			return next(ctx)
+		}
+	}
+}
+
+// verify checks the credentials without leaking
+// timing information about valid users or passwords.
+func (auth *BasicAuth) verify(user string, password string) bool {
+	if auth.Check != nil {
+		return auth.Check(user, password)
+	}
+
+	userHash := sha256.Sum256([]byte(user))
+	passwordHash := sha256.Sum256([]byte(password))
+	valid := 0
+
+	for expectedUser, expectedPassword := range auth.Users {
+		expectedUserHash := sha256.Sum256([]byte(expectedUser))
+		expectedPasswordHash := sha256.Sum256([]byte(expectedPassword))
+		userMatch := subtle.ConstantTimeCompare(userHash[:], expectedUserHash[:])
+		passwordMatch := subtle.ConstantTimeCompare(passwordHash[:], expectedPasswordHash[:])
+		valid |= userMatch & passwordMatch
+	}
+
+	return valid == 1
+}
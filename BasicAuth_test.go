@@ -0,0 +1,42 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestBasicAuth(t *testing.T) {
+	app := aero.New()
+	auth := &aero.BasicAuth{
+		Realm: "Admin",
+		Users: map[string]string{
+			"admin": "secret",
+		},
+	}
+
+	app.Get("/", aero.Handler(func(ctx aero.Context) error {
+		return ctx.Text(ctx.Principal().Subject)
+	}).Bind(auth.Middleware()))
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.SetBasicAuth("admin", "secret")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Body.String(), "admin")
+
+	request.SetBasicAuth("admin", "wrong")
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusUnauthorized)
+	assert.Contains(t, response.Header().Get("WWW-Authenticate"), `realm="Admin"`)
+
+	response = test(app, "/")
+	assert.Equal(t, response.Code, http.StatusUnauthorized)
+}
@@ -0,0 +1,178 @@
+package aero
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// CIDRSet is a set of CIDR blocks stored in a binary prefix trie.
+// Lookups only depend on the address length, not on the number of blocks.
+type CIDRSet struct {
+	ipv4 cidrNode
+	ipv6 cidrNode
+	size int
+}
+
+// cidrNode is a single bit in the prefix trie.
+type cidrNode struct {
+	children [2]*cidrNode
+	terminal bool
+}
+
+// NewCIDRSet creates a set containing the given CIDR blocks or IP addresses.
+func NewCIDRSet(cidrs ...string) (*CIDRSet, error) {
+	set := &CIDRSet{}
+
+	for _, cidr := range cidrs {
+		err := set.Add(cidr)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+// Add adds a CIDR block to the set.
+// Single IP addresses are treated as a block of size 1.
+func (set *CIDRSet) Add(cidr string) error {
+	cidr = strings.TrimSpace(cidr)
+
+	if !strings.ContainsRune(cidr, '/') {
+		ip := net.ParseIP(cidr)
+
+		if ip == nil {
+			return fmt.Errorf("Invalid IP address: '%s'", cidr)
+		}
+
+		bits := 8 * net.IPv6len
+
+		if ip.To4() != nil {
+			bits = 8 * net.IPv4len
+		}
+
+		set.AddNet(&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		return nil
+	}
+
+	_, block, err := net.ParseCIDR(cidr)
+
+	if err != nil {
+		return err
+	}
+
+	set.AddNet(block)
+	return nil
+}
+
+// AddNet adds a parsed CIDR block to the set.
+func (set *CIDRSet) AddNet(block *net.IPNet) {
+	root, ip := set.root(block.IP)
+	ones, bits := block.Mask.Size()
+
+	// IPv4 blocks written in IPv6 notation, e.g. ::ffff:10.0.0.0/104
+	if bits == 8*net.IPv6len && len(ip) == net.IPv4len {
+		ones -= 8 * (net.IPv6len - net.IPv4len)
+
+		if ones < 0 {
+			ones = 0
+		}
+	}
+
+	node := root
+
+	for i := 0; i < ones; i++ {
+		if node.terminal {
+			// A larger block already contains this one.
+			return
+		}
+
+		bit := ip[i/8] >> (7 - uint(i%8)) & 1
+
+		if node.children[bit] == nil {
+			node.children[bit] = &cidrNode{}
+		}
+
+		node = node.children[bit]
+	}
+
+	if !node.terminal {
+		// Smaller blocks covered by this one are removed.
+		set.size -= node.countTerminals()
+		node.terminal = true
+		node.children = [2]*cidrNode{}
+		set.size++
+	}
+}
+
+// countTerminals returns the number of blocks below the node.
+func (node *cidrNode) countTerminals() int {
+	count := 0
+
+	for _, child := range node.children {
+		if child == nil {
+			continue
+		}
+
+		if child.terminal {
+			count++
+			continue
+		}
+
+		count += child.countTerminals()
+	}
+
+	return count
+}
+
+// Contains reports whether the IP address is part of any block in the set.
+func (set *CIDRSet) Contains(address net.IP) bool {
+	if set == nil || address == nil {
+		return false
+	}
+
+	node, ip := set.root(address)
+
+	for i := 0; i < 8*len(ip); i++ {
+		if node.terminal {
+			return true
+		}
+
+		node = node.children[ip[i/8]>>(7-uint(i%8))&1]
+
+		if node == nil {
+			return false
+		}
+	}
+
+	return node.terminal
+}
+
+// ContainsString parses the IP address and reports whether
+// it is part of any block in the set.
+func (set *CIDRSet) ContainsString(address string) bool {
+	return set.Contains(net.ParseIP(address))
+}
+
+// Len returns the number of blocks in the set.
+// Blocks that are fully covered by larger blocks are not counted.
+func (set *CIDRSet) Len() int {
+	if set == nil {
+		return 0
+	}
+
+	return set.size
+}
+
+// root returns the trie for the address family and the normalized address.
+func (set *CIDRSet) root(address net.IP) (*cidrNode, net.IP) {
+	ipv4 := address.To4()
+
+	if ipv4 != nil {
+		return &set.ipv4, ipv4
+	}
+
+	return &set.ipv6, address.To16()
+}
@@ -0,0 +1,85 @@
+package aero_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestCIDRSet(t *testing.T) {
+	set, err := aero.NewCIDRSet(
+		"10.0.0.0/8",
+		"10.1.0.0/16",
+		"192.168.1.1",
+		"2001:db8::/32",
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, set.Len(), 3)
+
+	testData := map[string]bool{
+		"10.0.0.1":         true,
+		"10.255.255.255":   true,
+		"11.0.0.0":         false,
+		"192.168.1.1":      true,
+		"192.168.1.2":      false,
+		"::ffff:10.0.0.1":  true,
+		"2001:db8::1":      true,
+		"2001:db9::1":      false,
+		"::1":              false,
+		"not an ip":        false,
+		"172.16.0.1":       false,
+		"2001:db8:ffff::1": true,
+	}
+
+	for address, expected := range testData {
+		assert.Equal(t, set.ContainsString(address), expected)
+	}
+
+	_, err = aero.NewCIDRSet("10.0.0.0/33")
+	assert.NotNil(t, err)
+
+	_, err = aero.NewCIDRSet("10.0.0")
+	assert.NotNil(t, err)
+}
+
+func TestCIDRSetCoveredBlocks(t *testing.T) {
+	set, err := aero.NewCIDRSet(
+		"10.1.0.0/16",
+		"10.2.3.0/24",
+		"10.2.3.4",
+		"192.168.1.1",
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, set.Len(), 3)
+
+	// Larger blocks replace the smaller ones they cover
+	err = set.Add("10.0.0.0/8")
+	assert.Nil(t, err)
+	assert.Equal(t, set.Len(), 2)
+	assert.True(t, set.ContainsString("10.2.3.4"))
+	assert.True(t, set.ContainsString("10.200.0.1"))
+	assert.True(t, set.ContainsString("192.168.1.1"))
+}
+
+func BenchmarkCIDRSet(b *testing.B) {
+	set := &aero.CIDRSet{}
+
+	for i := 0; i < 10000; i++ {
+		err := set.Add(fmt.Sprintf("%d.%d.%d.0/24", 10+i/65536, (i/256)%256, i%256))
+
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		set.ContainsString("10.0.200.17")
+	}
+}
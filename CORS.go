@@ -0,0 +1,164 @@
+package aero
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORS configures cross-origin resource sharing.
+// Origins can be listed explicitly, as "*" to allow any origin
+// or as patterns like "https://*.example.com".
+// Empty method and header lists allow what the preflight request asks for.
+type CORS struct {
+	AllowOrigins     []string `json:"allowOrigins"`
+	AllowMethods     []string `json:"allowMethods"`
+	AllowHeaders     []string `json:"allowHeaders"`
+	ExposeHeaders    []string `json:"exposeHeaders"`
+	AllowCredentials bool     `json:"allowCredentials"`
+	MaxAge           Duration `json:"maxAge"`
+}
+
+// Validate checks the policy for insecure combinations.
+// Allowing any origin together with credentials would let every
+// site make credentialed requests, therefore it is rejected.
+func (cors *CORS) Validate() error {
+	if !cors.AllowCredentials {
+		return nil
+	}
+
+	for _, pattern := range cors.AllowOrigins {
+		if pattern == "*" {
+			return errors.New("CORS: allowOrigins \"*\" can not be combined with allowCredentials")
+		}
+	}
+
+	return nil
+}
+
+// Enabled reports whether any origin is allowed.
+func (cors *CORS) Enabled() bool {
+	return len(cors.AllowOrigins) > 0
+}
+
+// Middleware returns a middleware that applies this policy to the routes
+// it is bound to. Bind it to an OPTIONS route to answer preflight requests.
+// The application-wide policy in app.Config.CORS doesn't need this,
+// it is applied to every request and answers preflight requests automatically.
+func (cors *CORS) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context) error {
+			if cors.handle(ctx.Request().Internal(), ctx.Response().Internal()) {
+				return nil
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// handle sets the CORS headers for the request and
+// returns true if the request was a preflight request
+// that has been answered completely.
+func (cors *CORS) handle(request *http.Request, response http.ResponseWriter) bool {
+	origin := request.Header.Get(originHeader)
+	header := response.Header()
+	isPreflight := request.Method == http.MethodOptions && origin != "" && request.Header.Get(accessControlRequestMethodHeader) != ""
+
+	if isPreflight {
+		header.Add(varyHeader, originHeader)
+		header.Add(varyHeader, accessControlRequestMethodHeader)
+		header.Add(varyHeader, accessControlRequestHeadersHeader)
+	} else if origin != "" {
+		header.Add(varyHeader, originHeader)
+	}
+
+	if origin == "" {
+		return false
+	}
+
+	allowOrigin := cors.allowOrigin(origin)
+
+	if allowOrigin == "" {
+		if isPreflight {
+			response.WriteHeader(http.StatusNoContent)
+		}
+
+		return isPreflight
+	}
+
+	header.Set(accessControlAllowOriginHeader, allowOrigin)
+
+	// Credentials are never allowed for the wildcard
+	if cors.AllowCredentials && allowOrigin != "*" {
+		header.Set(accessControlAllowCredentialsHeader, "true")
+	}
+
+	if !isPreflight {
+		if len(cors.ExposeHeaders) > 0 {
+			header.Set(accessControlExposeHeadersHeader, strings.Join(cors.ExposeHeaders, ", "))
+		}
+
+		return false
+	}
+
+	if len(cors.AllowMethods) > 0 {
+		header.Set(accessControlAllowMethodsHeader, strings.Join(cors.AllowMethods, ", "))
+	} else {
+		header.Set(accessControlAllowMethodsHeader, request.Header.Get(accessControlRequestMethodHeader))
+	}
+
+	if len(cors.AllowHeaders) > 0 {
+		header.Set(accessControlAllowHeadersHeader, strings.Join(cors.AllowHeaders, ", "))
+	} else if requestHeaders := request.Header.Get(accessControlRequestHeadersHeader); requestHeaders != "" {
+		header.Set(accessControlAllowHeadersHeader, requestHeaders)
+	}
+
+	if cors.MaxAge.Duration > 0 {
+		header.Set(accessControlMaxAgeHeader, strconv.Itoa(int(cors.MaxAge.Seconds())))
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// allowOrigin returns the value of the Access-Control-Allow-Origin
+// header for the given origin or an empty string if it's not allowed.
+func (cors *CORS) allowOrigin(origin string) string {
+	for _, pattern := range cors.AllowOrigins {
+		if pattern == "*" {
+			return "*"
+		}
+
+		if matchOrigin(pattern, origin) {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// matchOrigin checks if the origin matches the pattern.
+// A single "*" in the pattern matches one or more subdomains.
+func matchOrigin(pattern string, origin string) bool {
+	star := strings.IndexByte(pattern, '*')
+
+	if star == -1 {
+		return strings.EqualFold(pattern, origin)
+	}
+
+	prefix := pattern[:star]
+	suffix := pattern[star+1:]
+
+	if len(origin) <= len(prefix)+len(suffix) {
+		return false
+	}
+
+	if !strings.EqualFold(origin[:len(prefix)], prefix) || !strings.EqualFold(origin[len(origin)-len(suffix):], suffix) {
+		return false
+	}
+
+	middle := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(middle, "/:@")
+}
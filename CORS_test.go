@@ -0,0 +1,114 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestCORS(t *testing.T) {
+	app := aero.New()
+	app.Config.CORS = aero.CORS{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org"},
+		ExposeHeaders:    []string{"X-Total"},
+		AllowCredentials: true,
+		MaxAge:           aero.Duration{Duration: 10 * time.Minute},
+	}
+
+	app.Post("/api", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	// Preflight
+	request := httptest.NewRequest("OPTIONS", "/api", nil)
+	request.Header.Set("Origin", "https://sub.example.org")
+	request.Header.Set("Access-Control-Request-Method", "POST")
+	request.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusNoContent)
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Origin"), "https://sub.example.org")
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Methods"), "POST")
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Headers"), "Content-Type")
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Credentials"), "true")
+	assert.Equal(t, response.Header().Get("Access-Control-Max-Age"), "600")
+
+	// Actual request
+	request = httptest.NewRequest("POST", "/api", nil)
+	request.Header.Set("Origin", "https://example.com")
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Body.String(), helloWorld)
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Origin"), "https://example.com")
+	assert.Equal(t, response.Header().Get("Access-Control-Expose-Headers"), "X-Total")
+	assert.Equal(t, response.Header().Get("Vary"), "Origin")
+
+	// Disallowed origins
+	for _, origin := range []string{"https://evil.com", "https://example.org", "https://evil.com/.example.org"} {
+		request = httptest.NewRequest("POST", "/api", nil)
+		request.Header.Set("Origin", origin)
+		response = httptest.NewRecorder()
+		app.ServeHTTP(response, request)
+
+		assert.Equal(t, response.Code, http.StatusOK)
+		assert.Equal(t, response.Header().Get("Access-Control-Allow-Origin"), "")
+	}
+}
+
+func TestCORSWildcard(t *testing.T) {
+	app := aero.New()
+	cors := &aero.CORS{
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{"GET", "POST"},
+	}
+
+	app.Get("/", aero.Handler(func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	}).Bind(cors.Middleware()))
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("Origin", "https://anywhere.com")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Origin"), "*")
+
+	// No CORS configuration, no CORS headers
+	response = test(app, "/404")
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Origin"), "")
+}
+
+func TestCORSWildcardCredentials(t *testing.T) {
+	cors := &aero.CORS{
+		AllowOrigins:     []string{"*"},
+		AllowCredentials: true,
+	}
+
+	assert.NotNil(t, cors.Validate())
+
+	// The wildcard is never turned into a reflected origin with credentials
+	app := aero.New()
+	app.Config.CORS = *cors
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("Origin", "https://evil.com")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Origin"), "*")
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Credentials"), "")
+
+	cors.AllowOrigins = []string{"https://example.com"}
+	assert.Nil(t, cors.Validate())
+}
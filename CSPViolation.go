@@ -0,0 +1,270 @@
+package aero
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aerogo/csp"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	// cspReportGroup is the name of the reporting endpoint group
+	// used in the report-to directive.
+	cspReportGroup = "csp-endpoint"
+
+	// cspReportMaxSize is the maximum accepted size of a report body.
+	cspReportMaxSize = 64 * 1024
+
+	// cspReportWindow defines how long an identical violation
+	// is suppressed after it has been reported once.
+	cspReportWindow = time.Minute
+
+	// cspReportMaxEntries limits the memory used for deduplication.
+	cspReportMaxEntries = 10000
+)
+
+// CSPViolation represents a single content security policy violation
+// as reported by the browser.
+type CSPViolation struct {
+	DocumentURI        string
+	Referrer           string
+	BlockedURI         string
+	ViolatedDirective  string
+	EffectiveDirective string
+	OriginalPolicy     string
+	Disposition        string
+	SourceFile         string
+	Sample             string
+	StatusCode         int
+	LineNumber         int
+	ColumnNumber       int
+}
+
+// cspLegacyReport is the body format used by the report-uri directive.
+type cspLegacyReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		Referrer           string `json:"referrer"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		OriginalPolicy     string `json:"original-policy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		ScriptSample       string `json:"script-sample"`
+		StatusCode         int    `json:"status-code"`
+		LineNumber         int    `json:"line-number"`
+		ColumnNumber       int    `json:"column-number"`
+	} `json:"csp-report"`
+}
+
+// cspReportingAPIReport is the body format used by the report-to directive.
+type cspReportingAPIReport struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		Referrer           string `json:"referrer"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		OriginalPolicy     string `json:"originalPolicy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		Sample             string `json:"sample"`
+		StatusCode         int    `json:"statusCode"`
+		LineNumber         int    `json:"lineNumber"`
+		ColumnNumber       int    `json:"columnNumber"`
+	} `json:"body"`
+}
+
+// parseCSPViolations parses both the legacy report-uri format
+// and the Reporting API format used by report-to.
+func parseCSPViolations(data []byte) ([]*CSPViolation, error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) == 0 {
+		return nil, errors.New("Empty report")
+	}
+
+	// Reporting API: a list of reports of different types
+	if data[0] == '[' {
+		var reports []cspReportingAPIReport
+		err := jsoniter.Unmarshal(data, &reports)
+
+		if err != nil {
+			return nil, err
+		}
+
+		violations := make([]*CSPViolation, 0, len(reports))
+
+		for _, report := range reports {
+			if report.Type != "csp-violation" {
+				continue
+			}
+
+			documentURI := report.Body.DocumentURL
+
+			if documentURI == "" {
+				documentURI = report.URL
+			}
+
+			violations = append(violations, &CSPViolation{
+				DocumentURI:        documentURI,
+				Referrer:           report.Body.Referrer,
+				BlockedURI:         report.Body.BlockedURL,
+				ViolatedDirective:  report.Body.EffectiveDirective,
+				EffectiveDirective: report.Body.EffectiveDirective,
+				OriginalPolicy:     report.Body.OriginalPolicy,
+				Disposition:        report.Body.Disposition,
+				SourceFile:         report.Body.SourceFile,
+				Sample:             report.Body.Sample,
+				StatusCode:         report.Body.StatusCode,
+				LineNumber:         report.Body.LineNumber,
+				ColumnNumber:       report.Body.ColumnNumber,
+			})
+		}
+
+		return violations, nil
+	}
+
+	// Legacy format: a single object wrapped in "csp-report"
+	report := cspLegacyReport{}
+	err := jsoniter.Unmarshal(data, &report)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Report.DocumentURI == "" && report.Report.ViolatedDirective == "" && report.Report.EffectiveDirective == "" {
+		return nil, errors.New("Invalid format: Expected CSP report")
+	}
+
+	effectiveDirective := report.Report.EffectiveDirective
+
+	if effectiveDirective == "" {
+		effectiveDirective = report.Report.ViolatedDirective
+	}
+
+	violation := &CSPViolation{
+		DocumentURI:        report.Report.DocumentURI,
+		Referrer:           report.Report.Referrer,
+		BlockedURI:         report.Report.BlockedURI,
+		ViolatedDirective:  report.Report.ViolatedDirective,
+		EffectiveDirective: effectiveDirective,
+		OriginalPolicy:     report.Report.OriginalPolicy,
+		Disposition:        report.Report.Disposition,
+		SourceFile:         report.Report.SourceFile,
+		Sample:             report.Report.ScriptSample,
+		StatusCode:         report.Report.StatusCode,
+		LineNumber:         report.Report.LineNumber,
+		ColumnNumber:       report.Report.ColumnNumber,
+	}
+
+	return []*CSPViolation{violation}, nil
+}
+
+// key returns the value used to detect duplicate reports.
+func (violation *CSPViolation) key() string {
+	return ETagString(strings.Join([]string{
+		violation.Disposition,
+		violation.DocumentURI,
+		violation.BlockedURI,
+		violation.EffectiveDirective,
+		violation.SourceFile,
+		strconv.Itoa(violation.LineNumber),
+		strconv.Itoa(violation.ColumnNumber),
+	}, "\n"))
+}
+
+// cspViolationFilter drops violations that have been seen recently.
+type cspViolationFilter struct {
+	mutex sync.Mutex
+	seen  map[string]time.Time
+}
+
+// isNew reports whether the violation has not been seen in the deduplication window.
+func (filter *cspViolationFilter) isNew(violation *CSPViolation, now time.Time) bool {
+	key := violation.key()
+
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	if filter.seen == nil {
+		filter.seen = make(map[string]time.Time)
+	}
+
+	last, exists := filter.seen[key]
+
+	if exists && now.Sub(last) < cspReportWindow {
+		return false
+	}
+
+	// Remove expired entries before the map gets too large
+	if len(filter.seen) >= cspReportMaxEntries {
+		for existingKey, seenTime := range filter.seen {
+			if now.Sub(seenTime) >= cspReportWindow {
+				delete(filter.seen, existingKey)
+			}
+		}
+
+		if len(filter.seen) >= cspReportMaxEntries {
+			filter.seen = make(map[string]time.Time)
+		}
+	}
+
+	filter.seen[key] = now
+	return true
+}
+
+// CSPReport registers a route that receives content security policy violation
+// reports and adds the report-uri and report-to directives pointing to it.
+// Call this after you've finished configuring your policies.
+func (app *Application) CSPReport(path string) {
+	app.cspReportPath = path
+
+	for _, policy := range []*csp.ContentSecurityPolicy{app.ContentSecurityPolicy, app.ContentSecurityPolicyReportOnly} {
+		if policy == nil {
+			continue
+		}
+
+		policy.Set("report-uri", path)
+		policy.Set("report-to", cspReportGroup)
+	}
+
+	app.Post(path, func(ctx Context) error {
+		data, err := ioutil.ReadAll(io.LimitReader(ctx.Request().Body().Reader(), cspReportMaxSize))
+
+		if err != nil {
+			return ctx.Error(http.StatusBadRequest, err)
+		}
+
+		violations, err := parseCSPViolations(data)
+
+		if err != nil {
+			return ctx.Error(http.StatusBadRequest, "Invalid CSP report", err)
+		}
+
+		now := time.Now()
+
+		for _, violation := range violations {
+			if !app.cspViolations.isNew(violation, now) {
+				continue
+			}
+
+			for _, callback := range app.onCSPViolation {
+				callback(ctx, violation)
+			}
+		}
+
+		ctx.SetStatus(http.StatusNoContent)
+		return ctx.Bytes(nil)
+	})
+}
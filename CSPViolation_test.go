@@ -0,0 +1,107 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/aerogo/csp"
+	"github.com/akyoto/assert"
+)
+
+const legacyReport = `{
+	"csp-report": {
+		"document-uri": "https://example.com/",
+		"referrer": "",
+		"blocked-uri": "https://evil.com/script.js",
+		"violated-directive": "script-src 'self'",
+		"effective-directive": "script-src",
+		"original-policy": "script-src 'self'",
+		"disposition": "enforce",
+		"line-number": 12
+	}
+}`
+
+const reportingAPIReport = `[
+	{
+		"type": "csp-violation",
+		"url": "https://example.com/",
+		"body": {
+			"documentURL": "https://example.com/",
+			"blockedURL": "inline",
+			"effectiveDirective": "style-src",
+			"disposition": "report"
+		}
+	},
+	{
+		"type": "deprecation",
+		"url": "https://example.com/",
+		"body": {}
+	}
+]`
+
+func TestCSPReport(t *testing.T) {
+	app := aero.New()
+	app.ContentSecurityPolicyReportOnly = csp.New()
+	app.ContentSecurityPolicyReportOnly.Set("default-src", "'self'")
+	app.CSPReport("/csp-report")
+
+	var violations []*aero.CSPViolation
+
+	app.OnCSPViolation(func(ctx aero.Context, violation *aero.CSPViolation) {
+		violations = append(violations, violation)
+	})
+
+	assert.Equal(t, app.ContentSecurityPolicy.Get("report-uri"), "/csp-report")
+	assert.Equal(t, app.ContentSecurityPolicyReportOnly.Get("report-to"), "csp-endpoint")
+
+	reports := []string{
+		legacyReport,
+		legacyReport,
+		reportingAPIReport,
+	}
+
+	for _, report := range reports {
+		request := httptest.NewRequest("POST", "/csp-report", strings.NewReader(report))
+		response := httptest.NewRecorder()
+		app.ServeHTTP(response, request)
+		assert.Equal(t, response.Code, http.StatusNoContent)
+	}
+
+	// The duplicate legacy report should have been dropped
+	assert.Equal(t, len(violations), 2)
+	assert.Equal(t, violations[0].BlockedURI, "https://evil.com/script.js")
+	assert.Equal(t, violations[0].EffectiveDirective, "script-src")
+	assert.Equal(t, violations[0].LineNumber, 12)
+	assert.Equal(t, violations[1].BlockedURI, "inline")
+	assert.Equal(t, violations[1].Disposition, "report")
+}
+
+func TestCSPReportInvalid(t *testing.T) {
+	app := aero.New()
+	app.CSPReport("/csp-report")
+
+	request := httptest.NewRequest("POST", "/csp-report", strings.NewReader("{}"))
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusBadRequest)
+}
+
+func TestCSPReportOnlyHeader(t *testing.T) {
+	app := aero.New()
+	app.Security.Load("testdata/fullchain.pem", "testdata/privkey.pem")
+	app.ContentSecurityPolicyReportOnly = csp.New()
+	app.ContentSecurityPolicyReportOnly.Set("default-src", "'none'")
+	app.CSPReport("/csp-report")
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.HTML(helloWorld)
+	})
+
+	response := test(app, "/")
+	assert.Contains(t, response.Header().Get("Content-Security-Policy"), "report-uri /csp-report;")
+	assert.Contains(t, response.Header().Get("Content-Security-Policy-Report-Only"), "default-src 'none';")
+	assert.Equal(t, response.Header().Get("Reporting-Endpoints"), `csp-endpoint="/csp-report"`)
+}
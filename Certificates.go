@@ -0,0 +1,192 @@
+package aero
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// certificateCheckInterval is how often the certificate files are checked for changes.
+const certificateCheckInterval = 10 * time.Second
+
+// certificateFiles is the path of a certificate and its key.
+type certificateFiles struct {
+	certificate string
+	key         string
+}
+
+// certificateStore selects the certificate for TLS handshakes
+// and reloads the certificates when the files change.
+type certificateStore struct {
+	files        []certificateFiles
+	mutex        sync.RWMutex
+	fallback     *tls.Certificate
+	names        map[string]*tls.Certificate
+	modification time.Time
+}
+
+// newCertificateStore loads the certificates from the given files.
+// The first certificate is used if no other one matches the host name.
+// The store is returned even if loading fails, so that the
+// certificates can be loaded later when the files have been fixed.
+func newCertificateStore(files []certificateFiles) (*certificateStore, error) {
+	store := &certificateStore{files: files}
+	return store, store.load()
+}
+
+// load reads all certificates and replaces the current ones.
+// If any certificate fails to load, the current ones are kept.
+func (store *certificateStore) load() error {
+	if len(store.files) == 0 {
+		return errors.New("No certificates configured")
+	}
+
+	// Failed files are only reported once until they change again
+	store.mutex.Lock()
+	store.modification = store.lastModification()
+	store.mutex.Unlock()
+
+	names := make(map[string]*tls.Certificate)
+	var fallback *tls.Certificate
+
+	for _, files := range store.files {
+		certificate, err := tls.LoadX509KeyPair(files.certificate, files.key)
+
+		if err != nil {
+			return fmt.Errorf("Failed loading certificate '%s': %v", files.certificate, err)
+		}
+
+		leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+
+		if err != nil {
+			return fmt.Errorf("Failed parsing certificate '%s': %v", files.certificate, err)
+		}
+
+		certificate.Leaf = leaf
+
+		if fallback == nil {
+			fallback = &certificate
+		}
+
+		hostNames := leaf.DNSNames
+
+		if len(hostNames) == 0 && leaf.Subject.CommonName != "" {
+			hostNames = []string{leaf.Subject.CommonName}
+		}
+
+		for _, name := range hostNames {
+			name = strings.ToLower(name)
+
+			// The first certificate for a name wins
+			if names[name] == nil {
+				names[name] = &certificate
+			}
+		}
+	}
+
+	store.mutex.Lock()
+	store.fallback = fallback
+	store.names = names
+	store.mutex.Unlock()
+	return nil
+}
+
+// GetCertificate returns the certificate for the requested host name.
+// Wildcard certificates match a single label, e.g. *.example.com.
+func (store *certificateStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	if store.fallback == nil {
+		return nil, errors.New("No certificates loaded")
+	}
+
+	certificate := store.names[name]
+
+	if certificate != nil {
+		return certificate, nil
+	}
+
+	dot := strings.IndexByte(name, '.')
+
+	if dot != -1 {
+		certificate = store.names["*"+name[dot:]]
+
+		if certificate != nil {
+			return certificate, nil
+		}
+	}
+
+	return store.fallback, nil
+}
+
+// changed reports whether any of the files have been modified since the last load.
+func (store *certificateStore) changed() bool {
+	store.mutex.RLock()
+	modification := store.modification
+	store.mutex.RUnlock()
+	return store.lastModification().After(modification)
+}
+
+// lastModification returns the latest modification time of the files.
+func (store *certificateStore) lastModification() time.Time {
+	latest := time.Time{}
+
+	for _, files := range store.files {
+		for _, path := range []string{files.certificate, files.key} {
+			info, err := os.Stat(path)
+
+			if err == nil && info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+		}
+	}
+
+	return latest
+}
+
+// watch reloads the certificates when the files change until the app shuts down.
+func (store *certificateStore) watch(app *Application) {
+	ticker := time.NewTicker(certificateCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.closing:
+			return
+
+		case <-ticker.C:
+			if !store.changed() {
+				continue
+			}
+
+			err := store.load()
+
+			if err != nil {
+				app.serverError(err)
+			}
+		}
+	}
+}
+
+// ReloadCertificates reads the certificate files again.
+// New TLS connections use the new certificates immediately.
+// If loading fails, the previous certificates stay in use.
+func (app *Application) ReloadCertificates() error {
+	app.serversMutex.Lock()
+	store := app.certificates
+	app.serversMutex.Unlock()
+
+	if store == nil {
+		return errors.New("No TLS listeners running")
+	}
+
+	return store.load()
+}
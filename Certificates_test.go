@@ -0,0 +1,163 @@
+package aero_test
+
+import (
+	stdContext "context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestCertificatesSNI(t *testing.T) {
+	directory, err := ioutil.TempDir("", "aero")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	defaultCert, defaultKey := writeCertificate(t, directory, "default", 1, "a.example.com")
+	wildcardCert, wildcardKey := writeCertificate(t, directory, "wildcard", 2, "*.b.example.com")
+
+	app := aero.New()
+	app.Security.Load(defaultCert, defaultKey)
+	app.Security.AddCertificate(wildcardCert, wildcardKey)
+	app.Config.Listeners = []aero.ListenerConfiguration{
+		{
+			Network: "tcp4",
+			Address: "127.0.0.1:0",
+			TLS:     true,
+		},
+	}
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	assert.Nil(t, app.Start(stdContext.Background()))
+	defer app.Stop(stdContext.Background())
+
+	address := app.Addresses()[0].String()
+	assert.Equal(t, handshake(t, address, "a.example.com").Int64(), int64(1))
+	assert.Equal(t, handshake(t, address, "x.b.example.com").Int64(), int64(2))
+	assert.Equal(t, handshake(t, address, "X.B.EXAMPLE.COM").Int64(), int64(2))
+	assert.Equal(t, handshake(t, address, "y.x.b.example.com").Int64(), int64(1))
+	assert.Equal(t, handshake(t, address, "unknown.example.com").Int64(), int64(1))
+
+	// Reload after the files changed
+	writeCertificate(t, directory, "default", 3, "a.example.com")
+	assert.Nil(t, app.ReloadCertificates())
+	assert.Equal(t, handshake(t, address, "a.example.com").Int64(), int64(3))
+
+	// Invalid files keep the old certificates
+	assert.Nil(t, ioutil.WriteFile(defaultCert, []byte("invalid"), 0600))
+	assert.NotNil(t, app.ReloadCertificates())
+	assert.Equal(t, handshake(t, address, "a.example.com").Int64(), int64(3))
+}
+
+func TestCertificatesInvalid(t *testing.T) {
+	directory, err := ioutil.TempDir("", "aero")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	certificatePath := filepath.Join(directory, "default.pem")
+	keyPath := filepath.Join(directory, "default.key")
+	var serverErrors []error
+
+	app := aero.New()
+	app.Security.Load(certificatePath, keyPath)
+	app.Config.Listeners = []aero.ListenerConfiguration{
+		{
+			Network: "tcp4",
+			Address: "127.0.0.1:0",
+		},
+		{
+			Network: "tcp4",
+			Address: "127.0.0.1:0",
+			TLS:     true,
+		},
+	}
+
+	app.OnServerError(func(err error) {
+		serverErrors = append(serverErrors, err)
+	})
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	// Missing certificates are reported instead of failing the start
+	assert.Nil(t, app.Start(stdContext.Background()))
+	defer app.Stop(stdContext.Background())
+	assert.Equal(t, len(serverErrors), 1)
+	assert.NotNil(t, app.ReloadCertificates())
+
+	// The HTTP listener keeps serving
+	response, err := http.Get("http://" + app.Addresses()[0].String() + "/")
+	assert.Nil(t, err)
+	response.Body.Close()
+	assert.Equal(t, response.StatusCode, http.StatusOK)
+
+	// HTTPS works once the certificates exist
+	_, err = tls.Dial("tcp", app.Addresses()[1].String(), &tls.Config{InsecureSkipVerify: true})
+	assert.NotNil(t, err)
+
+	writeCertificate(t, directory, "default", 1, "a.example.com")
+	assert.Nil(t, app.ReloadCertificates())
+	assert.Equal(t, handshake(t, app.Addresses()[1].String(), "a.example.com").Int64(), int64(1))
+}
+
+// handshake connects via TLS and returns the serial number of the server certificate.
+func handshake(t *testing.T, address string, serverName string) *big.Int {
+	connection, err := tls.Dial("tcp", address, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
+
+	assert.Nil(t, err)
+	defer connection.Close()
+	return connection.ConnectionState().PeerCertificates[0].SerialNumber
+}
+
+// writeCertificate creates a self-signed certificate and returns the paths of the certificate and the key.
+func writeCertificate(t *testing.T, directory string, name string, serial int64, hostName string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: hostName},
+		DNSNames:     []string{hostName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+
+	keyData, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certificatePath := filepath.Join(directory, name+".pem")
+	keyPath := filepath.Join(directory, name+".key")
+
+	err = ioutil.WriteFile(certificatePath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0600)
+	assert.Nil(t, err)
+
+	err = ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData}), 0600)
+	assert.Nil(t, err)
+
+	return certificatePath, keyPath
+}
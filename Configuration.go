@@ -2,15 +2,28 @@ package aero
 
 import (
 	"os"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 )
 
 // Configuration represents the data in your config.json file.
 type Configuration struct {
-	Push  []string          `json:"push"`
-	GZip  bool              `json:"gzip"`
-	Ports PortConfiguration `json:"ports"`
+	Push            []string                `json:"push"`
+	GZip            bool                    `json:"gzip"`
+	Ports           PortConfiguration       `json:"ports"`
+	Listeners       []ListenerConfiguration `json:"listeners"`
+	HTTPS           HTTPSConfiguration      `json:"https"`
+	TrustedProxies  []string                `json:"trustedProxies"`
+	IPFilter        IPFilterConfiguration   `json:"ipFilter"`
+	RateLimits      []RateLimit             `json:"rateLimits"`
+	CORS            CORS                    `json:"cors"`
+	Debug           bool                    `json:"debug"`
+	ServerTiming    bool                    `json:"serverTiming"`
+	Health          HealthConfiguration     `json:"health"`
+	Shutdown        ShutdownConfiguration   `json:"shutdown"`
+	GracefulRestart bool                    `json:"gracefulRestart"`
+	Prefork         int                     `json:"prefork"`
 }
 
 // PortConfiguration lets you configure the ports that Aero will listen on.
@@ -19,12 +32,26 @@ type PortConfiguration struct {
 	HTTPS int `json:"https"`
 }
 
+// ShutdownConfiguration controls the graceful shutdown.
+// Delay is the time between failing the readiness checks
+// and closing the listeners. It only applies when a readiness
+// route is served. Timeout is the time in-flight requests
+// have to finish after the listeners are closed.
+type ShutdownConfiguration struct {
+	Delay   Duration `json:"delay"`
+	Timeout Duration `json:"timeout"`
+}
+
 // Reset resets all fields to the default configuration.
 func (config *Configuration) Reset() {
 	config.Push = []string{}
 	config.GZip = true
 	config.Ports.HTTP = 4000
 	config.Ports.HTTPS = 4001
+	config.Health.Timeout.Duration = 5 * time.Second
+	config.Health.Cache.Duration = time.Second
+	config.Shutdown.Delay.Duration = 5 * time.Second
+	config.Shutdown.Timeout.Duration = 10 * time.Second
 }
 
 // LoadConfig loads the application configuration from the file system.
@@ -46,5 +73,11 @@ func LoadConfig(path string) (*Configuration, error) {
 		return nil, err
 	}
 
+	err = config.CORS.Validate()
+
+	if err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
@@ -8,11 +8,11 @@ import (
 	"io"
 	"io/ioutil"
 	"mime"
-	"net"
 	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/aerogo/session"
@@ -54,6 +54,7 @@ type Context interface {
 	JavaScript(string) error
 	JSON(interface{}) error
 	Path() string
+	Principal() *Principal
 	Query(param string) string
 	ReadAll(io.Reader) error
 	Reader(io.Reader) error
@@ -61,12 +62,19 @@ type Context interface {
 	Redirect(status int, url string) error
 	RemoteIP() string
 	Request() Request
+	RequestID() string
 	Response() Response
+	Route() string
 	Session() *session.Session
+	Set(key interface{}, value interface{})
+	SetPrincipal(*Principal)
 	SetStatus(int)
+	StartSpan(string) Span
 	Status() int
 	String(string) error
 	Text(string) error
+	Timing(name string, duration time.Duration, description string)
+	Value(key interface{}) interface{}
 }
 
 // context represents a request & response context.
@@ -76,12 +84,21 @@ type context struct {
 	request       request
 	response      response
 	session       *session.Session
-	handler       Handler
+	principal     *Principal
+	span          Span
+	rootSpan      Span
+	trace         requestTrace
+	errorRendered bool
+	values        valueStore
+	route         *route
 	paramNames    [maxParams]string
 	paramValues   [maxParams]string
 	paramCount    int
 	modifiers     [maxModifiers]Modifier
 	modifierCount int
+	timings       [maxTimings]serverTiming
+	timingCount   int
+	handlerStart  time.Time
 }
 
 // AddModifier adds a modifier that can change the response body
@@ -100,7 +117,7 @@ func (ctx *context) App() *Application {
 // text length is greater than the gzip threshold. Requires a byte slice.
 func (ctx *context) Bytes(body []byte) error {
 	// If the request has been canceled by the client, stop.
-	if ctx.request.Context().Err() != nil {
+	if ctx.request.inner.Context().Err() != nil {
 		return errors.New("Request interrupted by the client")
 	}
 
@@ -155,16 +172,36 @@ func (ctx *context) Bytes(body []byte) error {
 	}
 
 	// GZip
+	if ctx.app.Tracer != nil {
+		span, parent := ctx.startSpan("aero.gzip")
+		span.SetAttribute("size", len(body))
+		defer ctx.endSpan(span, parent)
+	}
+
 	header.Set(contentEncodingHeader, contentEncodingGzip)
-	ctx.response.inner.WriteHeader(ctx.status)
+	sizeBefore := ctx.response.Size()
+	var err error
 
-	// Write response body
-	writer := ctx.app.acquireGZipWriter(ctx.response.inner)
-	_, err := writer.Write(body)
-	writer.Close()
+	if ctx.app.Config.ServerTiming {
+		// Compress before the headers are sent
+		// so that the duration can be included.
+		err = ctx.timedGZip(body)
+	} else {
+		ctx.response.inner.WriteHeader(ctx.status)
 
-	// Put the writer back into the pool
-	ctx.app.gzipWriterPool.Put(writer)
+		// Write response body
+		writer := ctx.app.acquireGZipWriter(ctx.response.inner)
+		_, err = writer.Write(body)
+		writer.Close()
+
+		// Put the writer back into the pool
+		ctx.app.gzipWriterPool.Put(writer)
+	}
+
+	if ctx.app.metrics != nil {
+		atomic.AddUint64(&ctx.app.metrics.gzipInput, uint64(len(body)))
+		atomic.AddUint64(&ctx.app.metrics.gzipOutput, uint64(ctx.response.Size()-sizeBefore))
+	}
 
 	// Return the error value of the last Write call
 	return err
@@ -205,16 +242,7 @@ func (ctx *context) JSON(value interface{}) error {
 
 // HTML sends a HTML string.
 func (ctx *context) HTML(html string) error {
-	header := ctx.response.inner.Header()
-	header.Set(contentTypeHeader, contentTypeHTML)
-	header.Set(contentTypeOptionsHeader, contentTypeOptions)
-	header.Set(xssProtectionHeader, xssProtection)
-	header.Set(referrerPolicyHeader, referrerPolicySameOrigin)
-
-	if ctx.app.Security.Certificate != "" {
-		header.Set(strictTransportSecurityHeader, strictTransportSecurity)
-		header.Set(contentSecurityPolicyHeader, ctx.app.ContentSecurityPolicy.String())
-	}
+	ctx.response.SetHeader(contentTypeHeader, contentTypeHTML)
 
 	if len(ctx.app.Config.Push) > 0 {
 		err := ctx.pushResources()
@@ -232,6 +260,8 @@ func (ctx *context) HTML(html string) error {
 // Close frees up resources and is automatically called
 // in the ServeHTTP part of the web server.
 func (ctx *context) Close() {
+	ctx.values.reset()
+	ctx.request.values = nil
 	ctx.app.contextPool.Put(ctx)
 }
 
@@ -251,15 +281,21 @@ func (ctx *context) JavaScript(code string) error {
 func (ctx *context) EventStream(stream *EventStream) error {
 	defer close(stream.Closed)
 
+	if ctx.app.metrics != nil {
+		atomic.AddInt64(&ctx.app.metrics.eventStreams, 1)
+		defer atomic.AddInt64(&ctx.app.metrics.eventStreams, -1)
+	}
+
 	// Flush
 	flusher, ok := ctx.response.inner.(http.Flusher)
+	_, supported := unwrapResponseWriter(ctx.response.inner).(http.Flusher)
 
-	if !ok {
+	if !ok || !supported {
 		return ctx.Error(http.StatusNotImplemented, "Flushing not supported")
 	}
 
 	// Catch disconnect events
-	disconnectedContext := ctx.request.Context()
+	disconnectedContext := ctx.request.inner.Context()
 	disconnectedContext, cancel := stdContext.WithDeadline(disconnectedContext, time.Now().Add(2*time.Hour))
 	disconnected := disconnectedContext.Done()
 	defer cancel()
@@ -269,7 +305,6 @@ func (ctx *context) EventStream(stream *EventStream) error {
 	header.Set(contentTypeHeader, contentTypeEventStream)
 	header.Set(cacheControlHeader, "no-cache")
 	header.Set("Connection", "keep-alive")
-	header.Set("Access-Control-Allow-Origin", "*")
 	ctx.response.inner.WriteHeader(200)
 
 	for {
@@ -277,27 +312,40 @@ func (ctx *context) EventStream(stream *EventStream) error {
 		case <-disconnected:
 			return nil
 
+		case <-ctx.app.closing:
+			if stream.Final != nil {
+				ctx.writeEvent(stream.Final)
+				flusher.Flush()
+			}
+
+			return nil
+
 		case event := <-stream.Events:
 			if event != nil {
-				data := event.Data
+				ctx.writeEvent(event)
+				flusher.Flush()
+			}
+		}
+	}
+}
 
-				switch data.(type) {
-				case string, []byte:
-					// Do nothing with the data if it's already a string or byte slice.
-				default:
-					var err error
-					data, err = json.Marshal(data)
+// writeEvent writes a single event of an event stream.
+func (ctx *context) writeEvent(event *Event) {
+	data := event.Data
 
-					if err != nil {
-						color.Red("Failed encoding event data as JSON: %v", data)
-					}
-				}
+	switch data.(type) {
+	case string, []byte:
+		// Do nothing with the data if it's already a string or byte slice.
+	default:
+		var err error
+		data, err = json.Marshal(data)
 
-				fmt.Fprintf(ctx.response.inner, "event: %s\ndata: %s\n\n", event.Name, data)
-				flusher.Flush()
-			}
+		if err != nil {
+			color.Red("Failed encoding event data as JSON: %v", data)
 		}
 	}
+
+	fmt.Fprintf(ctx.response.inner, "event: %s\ndata: %s\n\n", event.Name, data)
 }
 
 // File sends the contents of a local file and determines its mime type by extension.
@@ -315,35 +363,30 @@ func (ctx *context) File(file string) error {
 }
 
 // Error should be used for sending error messages to the client.
+// Strings are joined to the message the client receives.
+// Errors are never shown to the client, the first one is
+// stored as the cause for the OnError callbacks.
 func (ctx *context) Error(statusCode int, errorList ...interface{}) error {
-	ctx.status = statusCode
-
-	if len(errorList) == 0 {
-		message := http.StatusText(statusCode)
-		_ = ctx.String(message)
-		return errors.New(message)
+	httpErr := &HTTPError{
+		Status: statusCode,
 	}
 
-	messageBuffer := strings.Builder{}
+	var messages []string
 
-	for index, param := range errorList {
+	for _, param := range errorList {
 		switch err := param.(type) {
 		case string:
-			messageBuffer.WriteString(err)
+			messages = append(messages, err)
 		case error:
-			messageBuffer.WriteString(err.Error())
-		default:
-			continue
-		}
-
-		if index != len(errorList)-1 {
-			messageBuffer.WriteString(": ")
+			if httpErr.Cause == nil {
+				httpErr.Cause = err
+			}
 		}
 	}
 
-	message := messageBuffer.String()
-	_ = ctx.String(message)
-	return errors.New(message)
+	httpErr.Message = strings.Join(messages, ": ")
+	_ = ctx.renderError(httpErr)
+	return httpErr
 }
 
 // Path returns the relative request path, e.g. /blog/post/123.
@@ -374,22 +417,14 @@ func (ctx *context) GetInt(param string) (int, error) {
 
 // IP tries to determine the real IP address of the client.
 func (ctx *context) IP() string {
-	return strings.Trim(realIP(ctx.request.inner), "[]")
+	return strings.Trim(realIP(ctx.request.inner, ctx.app.trustedProxies), "[]")
 }
 
 // RemoteIP returns the remote IP address. This will return
 // the IP address of the endpoint (e.g. a proxy) but not
 // necessarily the IP of the client.
 func (ctx *context) RemoteIP() string {
-	remoteIP := ctx.request.inner.RemoteAddr
-
-	// If there is a colon in the remote address,
-	// remove the port number.
-	if strings.ContainsRune(remoteIP, ':') {
-		remoteIP, _, _ = net.SplitHostPort(remoteIP)
-	}
-
-	return remoteIP
+	return remoteIP(ctx.request.inner)
 }
 
 // Query retrieves the value for the given URL query parameter.
@@ -436,7 +471,7 @@ func canCompress(contentType string) bool {
 // push will start pushing the given resources in a separate goroutine.
 func (ctx *context) push(paths ...string) error {
 	// Check if we can push
-	pusher, ok := ctx.response.inner.(http.Pusher)
+	pusher, ok := unwrapResponseWriter(ctx.response.inner).(http.Pusher)
 
 	if !ok {
 		return nil
@@ -449,7 +484,7 @@ func (ctx *context) push(paths ...string) error {
 
 	// Push every resource
 	for _, path := range paths {
-		err := pusher.Push(path, &ctx.app.pushOptions)
+		err := ctx.pushResource(pusher, path)
 
 		if err != nil {
 			return err
@@ -459,6 +494,26 @@ func (ctx *context) push(paths ...string) error {
 	return nil
 }
 
+// pushResource pushes a single resource.
+func (ctx *context) pushResource(pusher http.Pusher, path string) error {
+	span := ctx.StartSpan("aero.push")
+	span.SetAttribute("http.target", path)
+	defer span.End()
+
+	err := pusher.Push(path, &ctx.app.pushOptions)
+
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if ctx.app.metrics != nil {
+		atomic.AddUint64(&ctx.app.metrics.pushes, 1)
+	}
+
+	return nil
+}
+
 // pushResources will start pushing the given resources
 // in a separate goroutine if the defined conditions are true.
 func (ctx *context) pushResources() error {
@@ -521,8 +576,13 @@ func (ctx *context) ReadSeeker(reader io.ReadSeeker) error {
 	return nil
 }
 
-// Status returns the HTTP status.
+// Status returns the HTTP status that has been sent or,
+// before the headers are sent, the status that will be used.
 func (ctx *context) Status() int {
+	if ctx.response.HeadersSent() {
+		return ctx.response.Status()
+	}
+
 	return ctx.status
 }
 
@@ -537,16 +597,68 @@ func (ctx *context) String(body string) error {
 	return ctx.Bytes(unsafe.StringToBytes(body))
 }
 
+// Principal returns the authenticated identity of the request
+// or nil if no authentication middleware accepted the request.
+func (ctx *context) Principal() *Principal {
+	return ctx.principal
+}
+
+// SetPrincipal sets the authenticated identity of the request.
+// This is called by the authentication middleware.
+func (ctx *context) SetPrincipal(principal *Principal) {
+	ctx.principal = principal
+}
+
+// Set stores a value for the duration of the request.
+// Like with context.WithValue, keys should use their own
+// unexported type to avoid collisions between packages.
+// The values are also visible via Request().Context().
+func (ctx *context) Set(key interface{}, value interface{}) {
+	ctx.values.set(key, value)
+
+	// The request context is an immutable chain so that
+	// it can be safely kept after the request has finished.
+	parent := ctx.request.values
+
+	if parent == nil {
+		parent = ctx.request.inner.Context()
+	}
+
+	ctx.request.values = stdContext.WithValue(parent, key, value)
+}
+
+// Value returns the value stored for the key or nil if it doesn't exist.
+func (ctx *context) Value(key interface{}) interface{} {
+	value, _ := ctx.values.get(key)
+	return value
+}
+
 // Request returns the HTTP request.
 func (ctx *context) Request() Request {
 	return &ctx.request
 }
 
+// RequestID returns the ID assigned by the RequestID middleware.
+// It returns an empty string if the middleware isn't used.
+func (ctx *context) RequestID() string {
+	return ctx.trace.id
+}
+
 // Response returns the HTTP response.
 func (ctx *context) Response() Response {
 	return &ctx.response
 }
 
+// Route returns the pattern of the matched route, e.g. /user/:id.
+// It returns an empty string if no route matched the request.
+func (ctx *context) Route() string {
+	if ctx.route == nil {
+		return ""
+	}
+
+	return ctx.route.pattern
+}
+
 // Session returns the session of the context or creates and caches a new session.
 func (ctx *context) Session() *session.Session {
 	// Return cached session if available.
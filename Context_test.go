@@ -52,7 +52,8 @@ func TestContextError(t *testing.T) {
 
 	response := test(app, "/")
 	assert.Equal(t, response.Code, http.StatusUnauthorized)
-	assert.Contains(t, response.Body.String(), "Not logged in")
+	assert.Contains(t, response.Body.String(), "Not authorized")
+	assert.NotContains(t, response.Body.String(), "Not logged in")
 
 	response = test(app, "/explanation-only")
 	assert.Equal(t, response.Code, http.StatusUnauthorized)
@@ -474,6 +475,7 @@ func TestContextEventStream(t *testing.T) {
 	app.ServeHTTP(response, request)
 
 	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Header().Get("Access-Control-Allow-Origin"), "")
 }
 
 func TestBigResponse(t *testing.T) {
@@ -591,3 +593,53 @@ func TestBigResponse304(t *testing.T) {
 	assert.Equal(t, response.Code, 304)
 	assert.Equal(t, response.Body.String(), "")
 }
+
+func TestContextValues(t *testing.T) {
+	type key string
+	app := aero.New()
+
+	app.Use(func(next aero.Handler) aero.Handler {
+		return func(ctx aero.Context) error {
+			assert.Nil(t, ctx.Value(key("user")))
+			ctx.Set(key("user"), "alice")
+			ctx.Set(key("id"), 42)
+			ctx.Set(key("id"), 43)
+			return next(ctx)
+		}
+	})
+
+	var retained []context.Context
+
+	app.Get("/", func(ctx aero.Context) error {
+		assert.Equal(t, ctx.Value(key("id")), 43)
+		assert.Nil(t, ctx.Value("user"))
+
+		// Values are visible to libraries using the standard context
+		requestContext := ctx.Request().Context()
+		assert.Equal(t, requestContext.Value(key("user")), "alice")
+		assert.Equal(t, requestContext.Value(key("id")), 43)
+		assert.Nil(t, requestContext.Err())
+		retained = append(retained, requestContext)
+
+		// Later values must not change contexts handed out earlier
+		ctx.Set(key("user"), "bob")
+		assert.Equal(t, requestContext.Value(key("user")), "alice")
+		assert.Equal(t, ctx.Request().Context().Value(key("user")), "bob")
+
+		return ctx.Text(ctx.Value(key("user")).(string))
+	})
+
+	app.BindMiddleware()
+
+	// Pooled contexts must not leak values into the next request
+	for i := 0; i < 3; i++ {
+		response := test(app, "/")
+		assert.Equal(t, response.Code, http.StatusOK)
+		assert.Equal(t, response.Body.String(), "bob")
+	}
+
+	// Contexts kept after the request still see their own values
+	for _, requestContext := range retained {
+		assert.Equal(t, requestContext.Value(key("user")), "alice")
+	}
+}
@@ -0,0 +1,41 @@
+package aero
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Duration is a time.Duration that can be read from JSON strings
+// like "1m30s" or from numbers which are interpreted as seconds.
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (duration *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	err := json.Unmarshal(data, &value)
+
+	if err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case float64:
+		duration.Duration = time.Duration(value * float64(time.Second))
+		return nil
+
+	case string:
+		duration.Duration, err = time.ParseDuration(value)
+		return err
+
+	default:
+		return errors.New("Invalid duration: Expected string or number")
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (duration Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(duration.String())
+}
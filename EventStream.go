@@ -2,9 +2,13 @@ package aero
 
 // EventStream includes a channel of events that we can send to
 // and a closed channel that we can check for closed connections.
+// When the server shuts down, the Final event is sent if it's
+// not nil and the stream is closed, e.g. to tell clients when
+// to reconnect.
 type EventStream struct {
 	Events chan *Event
 	Closed chan struct{}
+	Final  *Event
 }
 
 // NewEventStream creates a new event stream.
@@ -0,0 +1,74 @@
+package aero
+
+import (
+	"net/http"
+	"strings"
+)
+
+// NotFound registers a handler for requests that don't match any route.
+// The handler runs through the middleware chain and the
+// response status is preset to 404 Not Found.
+func (app *Application) NotFound(handler Handler) {
+	app.notFound = &route{handler: handler}
+}
+
+// MethodNotAllowed registers a handler for requests whose path matches
+// a route of a different method. The handler runs through the middleware
+// chain, the response status is preset to 405 Method Not Allowed and the
+// Allow header lists the methods of the path.
+// Without this handler such requests are treated as not found.
+func (app *Application) MethodNotAllowed(handler Handler) {
+	app.methodNotAllowed = &route{handler: handler}
+}
+
+// ErrorPage registers a page for error responses with the given status.
+// It replaces the default error rendering for HTTPErrors returned by
+// handlers, for ctx.Error and for requests that don't match any route.
+// Calling ctx.Error inside the page falls back to the default rendering.
+func (app *Application) ErrorPage(status int, page func(Context, *HTTPError) error) {
+	if app.errorPages == nil {
+		app.errorPages = make(map[int]func(Context, *HTTPError) error)
+	}
+
+	app.errorPages[status] = page
+}
+
+// fallback assigns the MethodNotAllowed or NotFound route to a context
+// that didn't match any route. If no fallback route is registered,
+// it responds directly and returns false.
+func (app *Application) fallback(ctx *context) bool {
+	ctx.paramCount = 0
+
+	if app.methodNotAllowed != nil {
+		allowed := app.router.allowedMethods(ctx.request.inner.URL.Path)
+
+		if len(allowed) > 0 {
+			ctx.response.SetHeader(allowHeader, strings.Join(allowed, ", "))
+			ctx.status = http.StatusMethodNotAllowed
+			ctx.route = app.methodNotAllowed
+			return true
+		}
+	}
+
+	if app.notFound != nil {
+		ctx.status = http.StatusNotFound
+		ctx.route = app.notFound
+		return true
+	}
+
+	if app.errorPages[http.StatusNotFound] != nil {
+		httpErr := &HTTPError{Status: http.StatusNotFound}
+		err := ctx.renderError(httpErr)
+
+		if err != nil {
+			for _, callback := range app.onError {
+				callback(ctx, err)
+			}
+		}
+
+		return false
+	}
+
+	ctx.response.inner.WriteHeader(http.StatusNotFound)
+	return false
+}
@@ -0,0 +1,145 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestNotFound(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/user/:id", func(ctx aero.Context) error {
+		return ctx.Text(ctx.Get("id"))
+	})
+
+	app.NotFound(func(ctx aero.Context) error {
+		return ctx.Text("Nothing at " + ctx.Path())
+	})
+
+	app.Use(func(next aero.Handler) aero.Handler {
+		return func(ctx aero.Context) error {
+			ctx.Response().SetHeader("X-Middleware", "true")
+			return next(ctx)
+		}
+	})
+
+	app.BindMiddleware()
+	response := test(app, "/user/42/profile")
+
+	assert.Equal(t, response.Code, http.StatusNotFound)
+	assert.Equal(t, response.Body.String(), "Nothing at /user/42/profile")
+	assert.Equal(t, response.Header().Get("X-Middleware"), "true")
+
+	// Without a MethodNotAllowed handler, other methods are not found
+	request := httptest.NewRequest("POST", "/user/42", nil)
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusNotFound)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	app := aero.New()
+	handler := func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	}
+
+	app.Get("/user/:id", handler)
+	app.Put("/user/:id", handler)
+	app.Delete("/user/:id", handler)
+
+	app.MethodNotAllowed(func(ctx aero.Context) error {
+		return ctx.Text(ctx.Request().Method() + " not allowed")
+	})
+
+	app.BindMiddleware()
+
+	request := httptest.NewRequest("POST", "/user/42", nil)
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, response.Header().Get("Allow"), "GET, DELETE, PUT")
+	assert.Equal(t, response.Body.String(), "POST not allowed")
+
+	// Paths without any route are still not found
+	request = httptest.NewRequest("POST", "/post/42", nil)
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusNotFound)
+	assert.Equal(t, response.Header().Get("Allow"), "")
+	assert.Equal(t, response.Body.String(), "")
+
+	// Methods without any routes are not allowed either
+	request = httptest.NewRequest("PROPFIND", "/user/42", nil)
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, response.Header().Get("Allow"), "GET, DELETE, PUT")
+	assert.Equal(t, response.Body.String(), "PROPFIND not allowed")
+}
+
+func TestUnknownMethod(t *testing.T) {
+	app := aero.New()
+	var errs []error
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	app.OnError(func(ctx aero.Context, err error) {
+		errs = append(errs, err)
+	})
+
+	app.BindMiddleware()
+
+	for _, method := range []string{"PROPFIND", "FOO"} {
+		request := httptest.NewRequest(method, "/", nil)
+		response := httptest.NewRecorder()
+		app.ServeHTTP(response, request)
+		assert.Equal(t, response.Code, http.StatusNotFound)
+	}
+
+	assert.Equal(t, len(errs), 0)
+}
+
+func TestErrorPage(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/admin", func(ctx aero.Context) error {
+		return ctx.Error(http.StatusForbidden, "Admins only")
+	})
+
+	app.Get("/loop", func(ctx aero.Context) error {
+		return &aero.HTTPError{Status: http.StatusInternalServerError}
+	})
+
+	app.ErrorPage(http.StatusForbidden, func(ctx aero.Context, err *aero.HTTPError) error {
+		return ctx.HTML("<h1>Forbidden</h1><p>" + err.Message + "</p>")
+	})
+
+	app.ErrorPage(http.StatusNotFound, func(ctx aero.Context, err *aero.HTTPError) error {
+		return ctx.HTML("<h1>Page not found</h1>")
+	})
+
+	app.ErrorPage(http.StatusInternalServerError, func(ctx aero.Context, err *aero.HTTPError) error {
+		return ctx.Error(http.StatusInternalServerError, "Error page failed")
+	})
+
+	response := test(app, "/admin")
+	assert.Equal(t, response.Code, http.StatusForbidden)
+	assert.Equal(t, response.Body.String(), "<h1>Forbidden</h1><p>Admins only</p>")
+
+	response = test(app, "/404")
+	assert.Equal(t, response.Code, http.StatusNotFound)
+	assert.Equal(t, response.Body.String(), "<h1>Page not found</h1>")
+
+	response = test(app, "/loop")
+	assert.Equal(t, response.Code, http.StatusInternalServerError)
+	assert.Equal(t, response.Body.String(), "Error page failed")
+}
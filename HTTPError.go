@@ -0,0 +1,214 @@
+package aero
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HTTPError is an error with an HTTP status code.
+// Handlers can return it to respond with the given status.
+// The Message is shown to the client while the Cause is only
+// available to the OnError callbacks. Fields are added as extension
+// members to application/problem+json responses (RFC 7807).
+type HTTPError struct {
+	Status  int
+	Message string
+	Cause   error
+	Fields  map[string]interface{}
+}
+
+// Error returns the message followed by the cause.
+func (err *HTTPError) Error() string {
+	switch {
+	case err.Cause == nil:
+		return err.message()
+	case err.Message == "":
+		return err.Cause.Error()
+	default:
+		return err.Message + ": " + err.Cause.Error()
+	}
+}
+
+// Unwrap returns the cause of the error.
+func (err *HTTPError) Unwrap() error {
+	return err.Cause
+}
+
+// status returns the status code, defaulting to 500 Internal Server Error.
+func (err *HTTPError) status() int {
+	if err.Status < 400 || err.Status > 599 {
+		return http.StatusInternalServerError
+	}
+
+	return err.Status
+}
+
+// message returns the public message, defaulting to the status text.
+func (err *HTTPError) message() string {
+	if err.Message == "" {
+		return http.StatusText(err.status())
+	}
+
+	return err.Message
+}
+
+// renderError responds with the error page registered for the status
+// or in the format the client prefers: application/problem+json, HTML or plain text.
+// Only the public message is shown to the client, never the cause.
+func (ctx *context) renderError(err *HTTPError) error {
+	status := err.status()
+	title := http.StatusText(status)
+	detail := err.message()
+	ctx.status = status
+
+	// Custom error pages replace the default rendering
+	page := ctx.app.errorPages[status]
+
+	if page != nil && !ctx.errorRendered {
+		ctx.errorRendered = true
+		return page(ctx, err)
+	}
+
+	ctx.errorRendered = true
+
+	switch negotiateErrorType(ctx.request.Header(acceptHeader)) {
+	case contentTypeProblemJSON:
+		problem := make(map[string]interface{}, len(err.Fields)+4)
+		problem["type"] = "about:blank"
+		problem["title"] = title
+
+		for key, value := range err.Fields {
+			problem[key] = value
+		}
+
+		problem["status"] = status
+
+		if ctx.trace.id != "" {
+			problem["requestId"] = ctx.trace.id
+		}
+
+		if detail != title {
+			problem["detail"] = detail
+		}
+
+		body, encodeErr := json.Marshal(problem)
+
+		if encodeErr != nil {
+			return encodeErr
+		}
+
+		ctx.response.SetHeader(contentTypeHeader, contentTypeProblemJSON)
+		return ctx.Bytes(body)
+
+	case contentTypeHTML:
+		ctx.response.SetHeader(contentTypeHeader, contentTypeHTML)
+		heading := html.EscapeString(strconv.Itoa(status) + " " + title)
+		return ctx.String(fmt.Sprintf(errorPage, heading, heading, html.EscapeString(detail)))
+
+	default:
+		ctx.response.SetHeader(contentTypeHeader, contentTypePlainText)
+		return ctx.String(detail)
+	}
+}
+
+// negotiateErrorType returns the error format that best matches the Accept header.
+// Exact matches take precedence over wildcards with the same quality
+// and plain text is used if the client accepts anything.
+func negotiateErrorType(accept string) string {
+	if accept == "" {
+		return contentTypePlainText
+	}
+
+	candidates := [...]struct {
+		contentType string
+		mimeTypes   []string
+	}{
+		{contentTypePlainText, []string{"text/plain"}},
+		{contentTypeHTML, []string{"text/html", "application/xhtml+xml"}},
+		{contentTypeProblemJSON, []string{"application/problem+json", "application/json"}},
+	}
+
+	best := contentTypePlainText
+	bestQuality := -1.0
+	bestExact := false
+
+	for _, candidate := range candidates {
+		quality, exact := acceptQuality(accept, candidate.mimeTypes)
+
+		if quality <= 0 {
+			continue
+		}
+
+		if quality > bestQuality || (quality == bestQuality && exact && !bestExact) {
+			best = candidate.contentType
+			bestQuality = quality
+			bestExact = exact
+		}
+	}
+
+	return best
+}
+
+// acceptQuality returns the quality value the Accept header assigns
+// to the best matching MIME type and whether it was an exact match.
+func acceptQuality(accept string, mimeTypes []string) (float64, bool) {
+	bestQuality := 0.0
+	bestSpecificity := -1
+
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+
+			if strings.HasPrefix(param, "q=") {
+				value, err := strconv.ParseFloat(param[2:], 64)
+
+				if err == nil {
+					quality = value
+				}
+			}
+		}
+
+		for _, mimeType := range mimeTypes {
+			specificity := -1
+
+			switch {
+			case mediaRange == mimeType:
+				specificity = 2
+			case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mimeType, mediaRange[:len(mediaRange)-1]):
+				specificity = 1
+			case mediaRange == "*/*":
+				specificity = 0
+			}
+
+			// The most specific media range decides the quality
+			if specificity > bestSpecificity {
+				bestSpecificity = specificity
+				bestQuality = quality
+			}
+		}
+	}
+
+	return bestQuality, bestSpecificity == 2
+}
+
+// errorPage is the HTML page for error responses.
+const errorPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+</head>
+<body>
+<h1>%s</h1>
+<p>%s</p>
+</body>
+</html>
+`
@@ -0,0 +1,127 @@
+package aero_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestHTTPError(t *testing.T) {
+	app := aero.New()
+	cause := errors.New("sql: no rows in result set")
+	var reported error
+
+	app.Get("/user/:id", func(ctx aero.Context) error {
+		return &aero.HTTPError{
+			Status:  http.StatusNotFound,
+			Message: "User not found",
+			Cause:   cause,
+			Fields: map[string]interface{}{
+				"id":     ctx.Get("id"),
+				"status": 200,
+			},
+		}
+	})
+
+	app.OnError(func(ctx aero.Context, err error) {
+		reported = err
+	})
+
+	// Plain text
+	response := test(app, "/user/42")
+	assert.Equal(t, response.Code, http.StatusNotFound)
+	assert.Equal(t, response.Body.String(), "User not found")
+	assert.Equal(t, response.Header().Get("Content-Type"), "text/plain; charset=utf-8")
+
+	var httpErr *aero.HTTPError
+	assert.True(t, errors.As(reported, &httpErr))
+	assert.Equal(t, httpErr.Status, http.StatusNotFound)
+	assert.True(t, errors.Is(reported, cause))
+	assert.Equal(t, reported.Error(), "User not found: sql: no rows in result set")
+
+	// Problem details
+	response = testAccept(app, "/user/42", "application/json")
+	assert.Equal(t, response.Code, http.StatusNotFound)
+	assert.Equal(t, response.Header().Get("Content-Type"), "application/problem+json")
+
+	problem := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &problem))
+	assert.Equal(t, problem["type"], "about:blank")
+	assert.Equal(t, problem["title"], "Not Found")
+	assert.Equal(t, problem["status"], 404.0)
+	assert.Equal(t, problem["detail"], "User not found")
+	assert.Equal(t, problem["id"], "42")
+	assert.NotContains(t, response.Body.String(), "sql")
+
+	// HTML
+	response = testAccept(app, "/user/42", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	assert.Equal(t, response.Code, http.StatusNotFound)
+	assert.Equal(t, response.Header().Get("Content-Type"), "text/html; charset=utf-8")
+	assert.Contains(t, response.Body.String(), "<h1>404 Not Found</h1>")
+	assert.Contains(t, response.Body.String(), "User not found")
+}
+
+func TestHTTPErrorContextError(t *testing.T) {
+	app := aero.New()
+	var reported error
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Error(http.StatusForbidden, "Access denied", errors.New("<role> missing"))
+	})
+
+	app.OnError(func(ctx aero.Context, err error) {
+		reported = err
+	})
+
+	response := testAccept(app, "/", "text/html")
+	assert.Equal(t, response.Code, http.StatusForbidden)
+	assert.Contains(t, response.Body.String(), "Access denied")
+	assert.NotContains(t, response.Body.String(), "role")
+
+	httpErr, ok := reported.(*aero.HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, httpErr.Status, http.StatusForbidden)
+	assert.Equal(t, httpErr.Message, "Access denied")
+	assert.Equal(t, httpErr.Cause.Error(), "<role> missing")
+	assert.Equal(t, httpErr.Error(), "Access denied: <role> missing")
+}
+
+func TestHTTPErrorNegotiation(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/", func(ctx aero.Context) error {
+		return &aero.HTTPError{Status: http.StatusBadRequest}
+	})
+
+	accepts := map[string]string{
+		"":                                    "text/plain; charset=utf-8",
+		"*/*":                                 "text/plain; charset=utf-8",
+		"application/problem+json":            "application/problem+json",
+		"text/html;q=0.5, application/json":   "application/problem+json",
+		"text/*, application/json;q=0.9":      "text/plain; charset=utf-8",
+		"text/html, text/plain;q=0":           "text/html; charset=utf-8",
+		"image/webp, */*;q=0.1":               "text/plain; charset=utf-8",
+		"application/json, text/plain;q=0.99": "application/problem+json",
+	}
+
+	for accept, contentType := range accepts {
+		response := testAccept(app, "/", accept)
+		assert.Equal(t, response.Code, http.StatusBadRequest)
+		assert.Equal(t, response.Header().Get("Content-Type"), contentType)
+	}
+}
+
+func testAccept(app *aero.Application, route string, accept string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest("GET", route, nil)
+	request.Header.Set("Accept", accept)
+
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	return response
+}
@@ -0,0 +1,97 @@
+package aero
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HTTPSConfiguration controls the redirect from HTTP to HTTPS.
+// Exempt paths are still served via HTTP, e.g. ACME challenges
+// and health checks. Paths ending with a slash exempt everything
+// below them.
+type HTTPSConfiguration struct {
+	Redirect bool     `json:"redirect"`
+	Exempt   []string `json:"exempt"`
+}
+
+// isExempt reports whether the path may be served via HTTP.
+func (config *HTTPSConfiguration) isExempt(path string) bool {
+	for _, exempt := range config.Exempt {
+		if path == exempt || (strings.HasSuffix(exempt, "/") && strings.HasPrefix(path, exempt)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redirectToHTTPS sends a permanent redirect to the HTTPS version
+// of the URL if the request was made via HTTP. It reports whether
+// the redirect has been sent.
+func (app *Application) redirectToHTTPS(ctx *context) bool {
+	if ctx.request.Scheme() != "http" || app.Config.HTTPS.isExempt(ctx.request.inner.URL.Path) {
+		return false
+	}
+
+	host := ctx.request.Host()
+	hostname, _, err := net.SplitHostPort(host)
+
+	if err != nil {
+		// The host has no port
+		hostname = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+
+	// Browsers assume port 443 for HTTPS, every other port must be in the URL
+	port := app.httpsPort()
+
+	if port == "443" {
+		host = hostname
+
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+	} else {
+		host = net.JoinHostPort(hostname, port)
+	}
+
+	status := http.StatusPermanentRedirect
+
+	// Browsers only keep the method for 308, which old clients don't support
+	if ctx.request.inner.Method == http.MethodGet || ctx.request.inner.Method == http.MethodHead {
+		status = http.StatusMovedPermanently
+	}
+
+	_ = ctx.Redirect(status, "https://"+host+ctx.request.inner.URL.RequestURI())
+	return true
+}
+
+// httpsPort returns the port of the first TLS listener.
+func (app *Application) httpsPort() string {
+	app.serversMutex.Lock()
+	listeners := app.listeners
+	app.serversMutex.Unlock()
+
+	for index, config := range app.listenerConfigurations() {
+		if !config.TLS {
+			continue
+		}
+
+		if index < len(listeners) {
+			address, isTCP := listeners[index].Addr().(*net.TCPAddr)
+
+			if isTCP {
+				return strconv.Itoa(address.Port)
+			}
+		}
+
+		_, port, err := net.SplitHostPort(config.Address)
+
+		if err == nil {
+			return port
+		}
+	}
+
+	return strconv.Itoa(app.Config.Ports.HTTPS)
+}
@@ -0,0 +1,117 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestHTTPSRedirect(t *testing.T) {
+	app := aero.New()
+	app.Config.HTTPS.Redirect = true
+	app.Config.HTTPS.Exempt = []string{"/.well-known/acme-challenge/", "/healthz"}
+
+	handler := func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	}
+
+	app.Get("/", handler)
+	app.Post("/", handler)
+	app.Get("/healthz", handler)
+	app.Get("/healthz/details", handler)
+	app.Get("/.well-known/acme-challenge/:token", handler)
+
+	tests := []struct {
+		method   string
+		url      string
+		status   int
+		location string
+	}{
+		{"GET", "http://example.com:4000/?page=2&sort=asc", http.StatusMovedPermanently, "https://example.com:4001/?page=2&sort=asc"},
+		{"GET", "http://example.com/", http.StatusMovedPermanently, "https://example.com:4001/"},
+		{"GET", "http://[::1]:4000/", http.StatusMovedPermanently, "https://[::1]:4001/"},
+		{"POST", "http://example.com/", http.StatusPermanentRedirect, "https://example.com:4001/"},
+		{"GET", "http://example.com/healthz/details", http.StatusMovedPermanently, "https://example.com:4001/healthz/details"},
+		{"GET", "http://example.com/healthz", http.StatusOK, ""},
+		{"GET", "http://example.com/.well-known/acme-challenge/abc", http.StatusOK, ""},
+		{"GET", "https://example.com/", http.StatusOK, ""},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, test.url, nil)
+		response := httptest.NewRecorder()
+		app.ServeHTTP(response, request)
+		assert.Equal(t, response.Code, test.status)
+		assert.Equal(t, response.Header().Get("Location"), test.location)
+		assert.Equal(t, response.Header().Get("Strict-Transport-Security") != "", request.TLS != nil)
+	}
+}
+
+func TestHTTPSRedirectDefaultPort(t *testing.T) {
+	app := aero.New()
+	app.Config.HTTPS.Redirect = true
+	app.Config.Ports.HTTPS = 443
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	request := httptest.NewRequest("GET", "http://example.com:80/", nil)
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusMovedPermanently)
+	assert.Equal(t, response.Header().Get("Location"), "https://example.com/")
+}
+
+func TestHTTPSRedirectCustomPort(t *testing.T) {
+	app := aero.New()
+	app.Config.HTTPS.Redirect = true
+	app.Config.Ports.HTTPS = 8443
+
+	app.Get("/a", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	// The port is added even if the client used the default HTTP port
+	for _, url := range []string{"http://example.com/a?b=1", "http://example.com:80/a?b=1"} {
+		request := httptest.NewRequest("GET", url, nil)
+		response := httptest.NewRecorder()
+		app.ServeHTTP(response, request)
+		assert.Equal(t, response.Code, http.StatusMovedPermanently)
+		assert.Equal(t, response.Header().Get("Location"), "https://example.com:8443/a?b=1")
+	}
+
+	request := httptest.NewRequest("GET", "http://[::1]/a", nil)
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Header().Get("Location"), "https://[::1]:8443/a")
+}
+
+func TestHTTPSRedirectProxy(t *testing.T) {
+	app := aero.New()
+	app.Config.HTTPS.Redirect = true
+	app.Config.Ports.HTTPS = 443
+	assert.Nil(t, app.TrustProxies("192.0.2.1"))
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	// TLS has been terminated by the proxy
+	request := httptest.NewRequest("GET", "http://example.com/", nil)
+	request.Header.Set("X-Forwarded-Proto", "https")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.NotEqual(t, response.Header().Get("Strict-Transport-Security"), "")
+
+	request = httptest.NewRequest("GET", "http://example.com/", nil)
+	request.Header.Set("X-Forwarded-Proto", "http")
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusMovedPermanently)
+	assert.Equal(t, response.Header().Get("Location"), "https://example.com/")
+}
@@ -3,32 +3,62 @@ package aero
 // This list includes all the common header keys
 // and values used in the http server code.
 const (
-	cacheControlHeader            = "Cache-Control"
-	cacheControlAlwaysValidate    = "must-revalidate"
-	cacheControlMedia             = "public, max-age=13824000"
-	contentTypeOptionsHeader      = "X-Content-Type-Options"
-	contentTypeOptions            = "nosniff"
-	xssProtectionHeader           = "X-XSS-Protection"
-	xssProtection                 = "1; mode=block"
-	etagHeader                    = "ETag"
-	contentTypeHeader             = "Content-Type"
-	contentTypeHTML               = "text/html; charset=utf-8"
-	contentTypeCSS                = "text/css; charset=utf-8"
-	contentTypeJavaScript         = "application/javascript; charset=utf-8"
-	contentTypeJSON               = "application/json; charset=utf-8"
-	contentTypePlainText          = "text/plain; charset=utf-8"
-	contentTypeEventStream        = "text/event-stream; charset=utf-8"
-	contentTypeSVG                = "image/svg+xml"
-	contentEncodingHeader         = "Content-Encoding"
-	contentEncodingGzip           = "gzip"
-	acceptEncodingHeader          = "Accept-Encoding"
-	contentLengthHeader           = "Content-Length"
-	ifNoneMatchHeader             = "If-None-Match"
-	referrerPolicyHeader          = "Referrer-Policy"
-	referrerPolicySameOrigin      = "no-referrer"
-	strictTransportSecurityHeader = "Strict-Transport-Security"
-	strictTransportSecurity       = "max-age=31536000; includeSubDomains; preload"
-	contentSecurityPolicyHeader   = "Content-Security-Policy"
-	forwardedForHeader            = "X-Forwarded-For"
-	realIPHeader                  = "X-Real-Ip"
+	cacheControlHeader                  = "Cache-Control"
+	cacheControlAlwaysValidate          = "must-revalidate"
+	cacheControlMedia                   = "public, max-age=13824000"
+	contentTypeOptionsHeader            = "X-Content-Type-Options"
+	contentTypeOptions                  = "nosniff"
+	xssProtectionHeader                 = "X-XSS-Protection"
+	xssProtection                       = "1; mode=block"
+	etagHeader                          = "ETag"
+	contentTypeHeader                   = "Content-Type"
+	contentTypeHTML                     = "text/html; charset=utf-8"
+	contentTypeCSS                      = "text/css; charset=utf-8"
+	contentTypeJavaScript               = "application/javascript; charset=utf-8"
+	contentTypeJSON                     = "application/json; charset=utf-8"
+	contentTypePlainText                = "text/plain; charset=utf-8"
+	contentTypeEventStream              = "text/event-stream; charset=utf-8"
+	contentTypeProblemJSON              = "application/problem+json"
+	contentTypeSVG                      = "image/svg+xml"
+	contentEncodingHeader               = "Content-Encoding"
+	contentEncodingGzip                 = "gzip"
+	allowHeader                         = "Allow"
+	acceptHeader                        = "Accept"
+	acceptEncodingHeader                = "Accept-Encoding"
+	contentLengthHeader                 = "Content-Length"
+	ifNoneMatchHeader                   = "If-None-Match"
+	referrerPolicyHeader                = "Referrer-Policy"
+	referrerPolicySameOrigin            = "no-referrer"
+	strictTransportSecurityHeader       = "Strict-Transport-Security"
+	crossOriginOpenerPolicyHeader       = "Cross-Origin-Opener-Policy"
+	crossOriginEmbedderPolicyHeader     = "Cross-Origin-Embedder-Policy"
+	crossOriginResourcePolicyHeader     = "Cross-Origin-Resource-Policy"
+	permissionsPolicyHeader             = "Permissions-Policy"
+	contentSecurityPolicyHeader         = "Content-Security-Policy"
+	contentSecurityPolicyROHeader       = "Content-Security-Policy-Report-Only"
+	reportingEndpointsHeader            = "Reporting-Endpoints"
+	forwardedHeaderKey                  = "Forwarded"
+	forwardedForHeader                  = "X-Forwarded-For"
+	forwardedProtoHeader                = "X-Forwarded-Proto"
+	forwardedHostHeader                 = "X-Forwarded-Host"
+	realIPHeader                        = "X-Real-Ip"
+	rateLimitLimitHeader                = "RateLimit-Limit"
+	rateLimitRemainingHeader            = "RateLimit-Remaining"
+	rateLimitResetHeader                = "RateLimit-Reset"
+	retryAfterHeader                    = "Retry-After"
+	originHeader                        = "Origin"
+	varyHeader                          = "Vary"
+	accessControlAllowOriginHeader      = "Access-Control-Allow-Origin"
+	accessControlAllowCredentialsHeader = "Access-Control-Allow-Credentials"
+	accessControlAllowMethodsHeader     = "Access-Control-Allow-Methods"
+	accessControlAllowHeadersHeader     = "Access-Control-Allow-Headers"
+	accessControlExposeHeadersHeader    = "Access-Control-Expose-Headers"
+	accessControlMaxAgeHeader           = "Access-Control-Max-Age"
+	accessControlRequestMethodHeader    = "Access-Control-Request-Method"
+	accessControlRequestHeadersHeader   = "Access-Control-Request-Headers"
+	requestIDHeader                     = "X-Request-Id"
+	traceParentHeader                   = "traceparent"
+	authorizationHeader                 = "Authorization"
+	wwwAuthenticateHeader               = "WWW-Authenticate"
+	serverTimingHeader                  = "Server-Timing"
 )
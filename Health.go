@@ -0,0 +1,203 @@
+package aero
+
+import (
+	stdContext "context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Health check statuses.
+const (
+	HealthOK           = "ok"
+	HealthFail         = "fail"
+	HealthShuttingDown = "shutting down"
+)
+
+// HealthConfiguration controls how health checks are executed.
+// Checks that take longer than the timeout fail.
+// Results are reused for the cache duration so that frequent
+// probes don't overload the checked dependencies.
+type HealthConfiguration struct {
+	Timeout Duration `json:"timeout"`
+	Cache   Duration `json:"cache"`
+}
+
+// HealthReport is the JSON response of the health endpoints.
+type HealthReport struct {
+	Status string                        `json:"status"`
+	Checks map[string]*HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the result of a single health check.
+// The error message is only included in debug mode, failures
+// are always passed to the OnServerError callbacks.
+type HealthCheckResult struct {
+	Status     string    `json:"status"`
+	DurationMs float64   `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// healthCheck is a registered check with its cached result.
+type healthCheck struct {
+	name   string
+	check  func(stdContext.Context) error
+	mutex  sync.Mutex
+	result HealthCheckResult
+}
+
+// HealthCheck registers a check that must pass for the application to be ready,
+// e.g. a database ping. The context is canceled when the timeout is reached.
+func (app *Application) HealthCheck(name string, check func(stdContext.Context) error) {
+	app.healthChecks = append(app.healthChecks, &healthCheck{
+		name:  name,
+		check: check,
+	})
+}
+
+// ServeHealth registers the liveness and readiness routes.
+// Liveness only reports that the process is able to respond.
+// Readiness runs the registered health checks and responds with
+// 503 Service Unavailable if any of them fails or the application
+// is shutting down. While a readiness route is served, the shutdown
+// waits for the configured delay before closing the listeners.
+// An empty path skips the route.
+// The given middleware is only bound to the health routes.
+func (app *Application) ServeHealth(livenessPath string, readinessPath string, middleware ...Middleware) {
+	if livenessPath != "" {
+		liveness := Handler(func(ctx Context) error {
+			ctx.Response().SetHeader(cacheControlHeader, "no-store")
+			return ctx.JSON(&HealthReport{Status: HealthOK})
+		})
+
+		app.Get(livenessPath, liveness.Bind(middleware...))
+	}
+
+	if readinessPath != "" {
+		app.readiness = true
+
+		readiness := Handler(func(ctx Context) error {
+			report := app.Health()
+			ctx.Response().SetHeader(cacheControlHeader, "no-store")
+
+			if report.Status != HealthOK {
+				ctx.SetStatus(http.StatusServiceUnavailable)
+			}
+
+			return ctx.JSON(report)
+		})
+
+		app.Get(readinessPath, readiness.Bind(middleware...))
+	}
+}
+
+// Health runs all health checks concurrently and returns the report.
+func (app *Application) Health() *HealthReport {
+	if app.IsShuttingDown() {
+		return &HealthReport{Status: HealthShuttingDown}
+	}
+
+	report := &HealthReport{
+		Status: HealthOK,
+		Checks: make(map[string]*HealthCheckResult, len(app.healthChecks)),
+	}
+
+	results := make([]HealthCheckResult, len(app.healthChecks))
+	wg := sync.WaitGroup{}
+
+	for index, check := range app.healthChecks {
+		wg.Add(1)
+
+		go func(index int, check *healthCheck) {
+			defer wg.Done()
+			results[index] = check.run(app)
+		}(index, check)
+	}
+
+	wg.Wait()
+
+	for index, check := range app.healthChecks {
+		result := &results[index]
+		report.Checks[check.name] = result
+
+		// Error messages can reveal internals
+		if !app.Config.Debug {
+			result.Error = ""
+		}
+
+		if result.Status != HealthOK {
+			report.Status = HealthFail
+		}
+	}
+
+	return report
+}
+
+// IsShuttingDown reports whether the shutdown has started.
+func (app *Application) IsShuttingDown() bool {
+	return atomic.LoadInt32(&app.shuttingDown) == 1
+}
+
+// run returns the cached result or executes the check.
+// Every failed run is reported to the OnServerError callbacks,
+// cached results are not reported again.
+func (check *healthCheck) run(app *Application) HealthCheckResult {
+	config := app.Config.Health
+	check.mutex.Lock()
+	defer check.mutex.Unlock()
+
+	now := time.Now()
+
+	if !check.result.Time.IsZero() && now.Sub(check.result.Time) < config.Cache.Duration {
+		return check.result
+	}
+
+	ctx := stdContext.Background()
+
+	if config.Timeout.Duration > 0 {
+		var cancel stdContext.CancelFunc
+		ctx, cancel = stdContext.WithTimeout(ctx, config.Timeout.Duration)
+		defer cancel()
+	}
+
+	// The check runs in its own goroutine so that
+	// checks ignoring the context can't block the probe.
+	done := make(chan error, 1)
+
+	go func() {
+		// A panicking check fails instead of crashing the server
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("Health check panicked: %v", recovered)
+			}
+		}()
+
+		done <- check.check(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.New("Health check timed out")
+	}
+
+	check.result = HealthCheckResult{
+		Status:     HealthOK,
+		DurationMs: float64(time.Since(now)) / float64(time.Millisecond),
+		Time:       now,
+	}
+
+	if err != nil {
+		check.result.Status = HealthFail
+		check.result.Error = err.Error()
+		app.serverError(fmt.Errorf("Health check '%s' failed: %w", check.name, err))
+	}
+
+	return check.result
+}
@@ -0,0 +1,104 @@
+package aero_test
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestHealth(t *testing.T) {
+	app := aero.New()
+	app.Config.Health.Timeout.Duration = 50 * time.Millisecond
+	calls := int32(0)
+	serverErrors := int32(0)
+
+	app.OnServerError(func(err error) {
+		atomic.AddInt32(&serverErrors, 1)
+	})
+
+	app.HealthCheck("db", func(ctx stdContext.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+
+	app.ServeHealth("/healthz", "/readyz")
+
+	response := test(app, "/readyz")
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Header().Get("Cache-Control"), "no-store")
+
+	report := aero.HealthReport{}
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &report))
+	assert.Equal(t, report.Status, aero.HealthOK)
+	assert.Equal(t, report.Checks["db"].Status, aero.HealthOK)
+
+	// Results are cached
+	test(app, "/readyz")
+	assert.Equal(t, atomic.LoadInt32(&calls), int32(1))
+
+	app.HealthCheck("cache", func(ctx stdContext.Context) error {
+		return errors.New("connection refused")
+	})
+
+	app.HealthCheck("slow", func(ctx stdContext.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	app.HealthCheck("panic", func(ctx stdContext.Context) error {
+		panic("nil map")
+	})
+
+	response = test(app, "/readyz")
+	assert.Equal(t, response.Code, http.StatusServiceUnavailable)
+
+	report = aero.HealthReport{}
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &report))
+	assert.Equal(t, report.Status, aero.HealthFail)
+	assert.Equal(t, report.Checks["db"].Status, aero.HealthOK)
+	assert.Equal(t, report.Checks["cache"].Status, aero.HealthFail)
+	assert.Equal(t, report.Checks["slow"].Status, aero.HealthFail)
+	assert.Equal(t, report.Checks["panic"].Status, aero.HealthFail)
+
+	// Error messages are only shown in debug mode
+	assert.NotContains(t, response.Body.String(), "connection refused")
+	assert.Equal(t, atomic.LoadInt32(&serverErrors), int32(3))
+
+	app.Config.Debug = true
+	report = *app.Health()
+	assert.Equal(t, report.Checks["cache"].Error, "connection refused")
+	assert.Equal(t, report.Checks["slow"].Error, "Health check timed out")
+	assert.Equal(t, report.Checks["panic"].Error, "Health check panicked: nil map")
+
+	// Liveness doesn't depend on the checks
+	response = test(app, "/healthz")
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Contains(t, response.Body.String(), `"status":"ok"`)
+}
+
+func TestHealthShutdown(t *testing.T) {
+	app := aero.New()
+	app.Config.Shutdown.Delay.Duration = 0
+	app.ServeHealth("/healthz", "/readyz")
+
+	response := test(app, "/readyz")
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.False(t, app.IsShuttingDown())
+
+	app.Shutdown()
+	assert.True(t, app.IsShuttingDown())
+
+	response = test(app, "/readyz")
+	assert.Equal(t, response.Code, http.StatusServiceUnavailable)
+	assert.Contains(t, response.Body.String(), `"status":"shutting down"`)
+
+	response = test(app, "/healthz")
+	assert.Equal(t, response.Code, http.StatusOK)
+}
@@ -14,6 +14,10 @@ import (
 // Here we'll get the private CIDR blocks.
 var privateCIDRs = getPrivateCIDRs()
 
+// loopbackCIDRs contains the loopback blocks which
+// are the only proxies trusted by default.
+var loopbackCIDRs, _ = NewCIDRSet("127.0.0.1/8", "::1/128")
+
 // isPrivateAddress checks if the address is under private CIDR blocks.
 func isPrivateAddress(address string) (bool, error) {
 	ipAddress := net.ParseIP(address)
@@ -22,17 +26,11 @@ func isPrivateAddress(address string) (bool, error) {
 		return false, errors.New("Address is not valid")
 	}
 
-	for _, cidr := range privateCIDRs {
-		if cidr.Contains(ipAddress) {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return privateCIDRs.Contains(ipAddress), nil
 }
 
-// getPrivateCIDRs returns the list of private CIDR blocks.
-func getPrivateCIDRs() []*net.IPNet {
+// getPrivateCIDRs returns the set of private CIDR blocks.
+func getPrivateCIDRs() *CIDRSet {
 	maxCidrBlocks := []string{
 		"127.0.0.1/8",    // localhost
 		"10.0.0.0/8",     // 24-bit block
@@ -44,39 +42,89 @@ func getPrivateCIDRs() []*net.IPNet {
 		"fe80::/10",      // link local address IPv6
 	}
 
-	cidrs := make([]*net.IPNet, len(maxCidrBlocks))
+	cidrs, _ := NewCIDRSet(maxCidrBlocks...)
+	return cidrs
+}
+
+// forwardedElement is a single proxy hop in the RFC 7239 Forwarded header.
+type forwardedElement struct {
+	For   string
+	Proto string
+	Host  string
+}
+
+// parseForwarded parses all values of the RFC 7239 Forwarded header.
+func parseForwarded(values []string) []forwardedElement {
+	var elements []forwardedElement
+
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			element := forwardedElement{}
+
+			for _, pair := range strings.Split(part, ";") {
+				equal := strings.IndexByte(pair, '=')
 
-	for i, maxCidrBlock := range maxCidrBlocks {
-		_, cidr, _ := net.ParseCIDR(maxCidrBlock)
-		cidrs[i] = cidr
+				if equal == -1 {
+					continue
+				}
+
+				key := strings.ToLower(strings.TrimSpace(pair[:equal]))
+				value := strings.Trim(strings.TrimSpace(pair[equal+1:]), `"`)
+
+				switch key {
+				case "for":
+					element.For = stripPort(value)
+				case "proto":
+					element.Proto = strings.ToLower(value)
+				case "host":
+					element.Host = value
+				}
+			}
+
+			elements = append(elements, element)
+		}
 	}
 
-	return cidrs
+	return elements
 }
 
-// realIP returns the client's real public IP address from http request headers.
-func realIP(r *http.Request) string {
-	xForwardedFor := r.Header.Get(forwardedForHeader)
+// stripPort removes the port and IPv6 brackets from a forwarded address.
+func stripPort(address string) string {
+	if strings.HasPrefix(address, "[") {
+		end := strings.IndexByte(address, ']')
 
-	// Check the list of IP addresses in the X-Forwarded-For
-	// header and return the first global address, if available.
-	for _, address := range strings.Split(xForwardedFor, ",") {
-		address = strings.TrimSpace(address)
-		isPrivate, err := isPrivateAddress(address)
-
-		if err == nil && !isPrivate {
+		if end == -1 {
 			return address
 		}
+
+		return address[1:end]
 	}
 
-	// Return the X-Real-Ip header, if available.
-	xRealIP := r.Header.Get(realIPHeader)
+	// IPv4 with port, IPv6 addresses have more than one colon
+	if strings.Count(address, ":") == 1 {
+		address, _, _ = net.SplitHostPort(address)
+	}
+
+	return address
+}
 
-	if xRealIP != "" {
-		return xRealIP
+// splitHeader returns the comma separated values of a header.
+func splitHeader(value string) []string {
+	if value == "" {
+		return nil
 	}
 
-	// If both headers failed, return the remote IP.
+	values := strings.Split(value, ",")
+
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+
+	return values
+}
+
+// remoteIP returns the IP address of the direct peer.
+func remoteIP(r *http.Request) string {
 	remoteIP := r.RemoteAddr
 
 	// If there is a colon in the remote address,
@@ -87,3 +135,143 @@ func realIP(r *http.Request) string {
 
 	return remoteIP
 }
+
+// trustedPeer reports whether the direct peer is allowed to set forwarding headers.
+// Peers connected via a Unix domain socket have no address ("@" on Linux)
+// and are local processes like a reverse proxy, so they are always trusted.
+func trustedPeer(remote string, trusted *CIDRSet) bool {
+	if remote == "" || remote == "@" {
+		return true
+	}
+
+	return trusted.ContainsString(remote)
+}
+
+// clientHop walks the list of proxy hops from right to left and returns
+// the index of the first hop that is not a trusted proxy.
+// If all hops are trusted, the leftmost hop is returned.
+// The returned index is -1 if the direct peer reported an invalid address.
+func clientHop(hops []string, trusted *CIDRSet) int {
+	for i := len(hops) - 1; i >= 0; i-- {
+		if trusted.ContainsString(hops[i]) {
+			continue
+		}
+
+		if net.ParseIP(hops[i]) == nil {
+			// The hop is unknown or obfuscated, so we
+			// return the last proxy that we can trust.
+			if i+1 < len(hops) {
+				return i + 1
+			}
+
+			return -1
+		}
+
+		return i
+	}
+
+	return 0
+}
+
+// forwardedHeader returns the value of a forwarding header set by the trusted proxy chain.
+// Forwarded takes precedence over the X-Forwarded-* headers.
+func forwardedHeader(r *http.Request, trusted *CIDRSet, key string, legacyHeader string) string {
+	if !trustedPeer(remoteIP(r), trusted) {
+		return ""
+	}
+
+	elements := parseForwarded(r.Header[forwardedHeaderKey])
+
+	if len(elements) > 0 {
+		hops := make([]string, len(elements))
+
+		for i, element := range elements {
+			hops[i] = element.For
+		}
+
+		index := clientHop(hops, trusted)
+
+		if index < 0 {
+			index = len(elements) - 1
+		}
+
+		switch key {
+		case "proto":
+			return elements[index].Proto
+		case "host":
+			return elements[index].Host
+		}
+
+		return ""
+	}
+
+	values := splitHeader(r.Header.Get(legacyHeader))
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	// If every proxy appended its value, use the one
+	// that belongs to the same hop as the client address.
+	hops := splitHeader(r.Header.Get(forwardedForHeader))
+
+	if len(hops) == len(values) {
+		index := clientHop(hops, trusted)
+
+		if index >= 0 {
+			return values[index]
+		}
+	}
+
+	// Otherwise the closest proxy's value is the most trustworthy one.
+	return values[len(values)-1]
+}
+
+// realIP returns the client's real IP address.
+// Forwarding headers are only respected if the direct peer is a trusted proxy
+// or connected via a Unix domain socket.
+// The proxy chain is then walked from right to left and the first address
+// that is not a trusted proxy is considered to be the client.
+func realIP(r *http.Request, trusted *CIDRSet) string {
+	remote := remoteIP(r)
+
+	if !trustedPeer(remote, trusted) {
+		return remote
+	}
+
+	var hops []string
+	elements := parseForwarded(r.Header[forwardedHeaderKey])
+
+	if len(elements) > 0 {
+		hops = make([]string, len(elements))
+
+		for i, element := range elements {
+			hops[i] = element.For
+		}
+	} else {
+		hops = splitHeader(r.Header.Get(forwardedForHeader))
+
+		for i := range hops {
+			hops[i] = stripPort(hops[i])
+		}
+	}
+
+	if len(hops) == 0 {
+		// Return the X-Real-Ip header, if available.
+		xRealIP := r.Header.Get(realIPHeader)
+
+		if xRealIP != "" {
+			return xRealIP
+		}
+
+		return remote
+	}
+
+	index := clientHop(hops, trusted)
+
+	if index < 0 {
+		return remote
+	}
+
+	return hops[index]
+}
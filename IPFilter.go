@@ -0,0 +1,224 @@
+package aero
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// IPFilterConfiguration lists the CIDR blocks or IP addresses that are
+// allowed or denied. The file, if specified, uses the same JSON format
+// and its lists are added to the ones in the configuration.
+type IPFilterConfiguration struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+	File  string   `json:"file"`
+}
+
+// IPFilter allows or denies requests based on the client IP.
+// Denied addresses take precedence over allowed addresses.
+// If the allow list is empty, every address that is not denied is allowed.
+type IPFilter struct {
+	config IPFilterConfiguration
+	lists  atomic.Value
+}
+
+// ipFilterLists is the immutable state swapped on every reload.
+type ipFilterLists struct {
+	allow   *CIDRSet
+	deny    *CIDRSet
+	modTime time.Time
+}
+
+// NewIPFilter creates a new IP filter from the given configuration.
+func NewIPFilter(config IPFilterConfiguration) (*IPFilter, error) {
+	filter := &IPFilter{
+		config: config,
+	}
+
+	err := filter.Reload()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// LoadIPFilter creates a new IP filter from a JSON file.
+func LoadIPFilter(path string) (*IPFilter, error) {
+	return NewIPFilter(IPFilterConfiguration{
+		File: path,
+	})
+}
+
+// InternalOnly returns a middleware that only allows
+// requests from loopback and private network addresses.
+func InternalOnly() Middleware {
+	filter := &IPFilter{}
+	filter.lists.Store(&ipFilterLists{
+		allow: privateCIDRs,
+	})
+
+	return filter.Middleware()
+}
+
+// Reload re-reads the file and atomically replaces the lists.
+// Requests are served with the old lists until the new ones are ready.
+// If the file can not be parsed, the old lists stay in place.
+func (filter *IPFilter) Reload() error {
+	allow := filter.config.Allow
+	deny := filter.config.Deny
+	lists := &ipFilterLists{}
+
+	if filter.config.File != "" {
+		stat, err := os.Stat(filter.config.File)
+
+		if err != nil {
+			return err
+		}
+
+		file, err := LoadIPFilterConfig(filter.config.File)
+
+		if err != nil {
+			return err
+		}
+
+		allow = append(append([]string{}, allow...), file.Allow...)
+		deny = append(append([]string{}, deny...), file.Deny...)
+		lists.modTime = stat.ModTime()
+	}
+
+	var err error
+	lists.allow, err = NewCIDRSet(allow...)
+
+	if err != nil {
+		return err
+	}
+
+	lists.deny, err = NewCIDRSet(deny...)
+
+	if err != nil {
+		return err
+	}
+
+	filter.lists.Store(lists)
+	return nil
+}
+
+// Watch checks the file for modifications in the given interval
+// and reloads the lists when it changes. Errors are passed to the
+// optional error callback. Call the returned function to stop watching.
+// Without a configured file there is nothing to watch.
+func (filter *IPFilter) Watch(interval time.Duration, onError func(error)) (stop func()) {
+	if filter.config.File == "" {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		// Broken or missing files are only reported once until they change again
+		checked := filter.lists.Load().(*ipFilterLists).modTime
+		missing := false
+
+		for {
+			select {
+			case <-done:
+				return
+
+			case <-ticker.C:
+				stat, err := os.Stat(filter.config.File)
+
+				if err != nil {
+					if !missing && onError != nil {
+						onError(err)
+					}
+
+					missing = true
+					continue
+				}
+
+				missing = false
+
+				if stat.ModTime().Equal(checked) {
+					continue
+				}
+
+				checked = stat.ModTime()
+				err = filter.Reload()
+
+				if err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// Allowed reports whether the given IP address passes the filter.
+func (filter *IPFilter) Allowed(address string) bool {
+	lists := filter.lists.Load().(*ipFilterLists)
+	ip := net.ParseIP(address)
+
+	if ip == nil {
+		return lists.allow.Len() == 0 && lists.deny.Len() == 0
+	}
+
+	if lists.deny.Contains(ip) {
+		return false
+	}
+
+	if lists.allow.Len() == 0 {
+		return true
+	}
+
+	return lists.allow.Contains(ip)
+}
+
+// Middleware returns a middleware that responds with
+// 403 Forbidden when the client IP doesn't pass the filter.
+// Use it with app.Use or bind it to specific routes.
+func (filter *IPFilter) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context) error {
+			if !filter.Allowed(ctx.IP()) {
+				return ctx.Error(http.StatusForbidden)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// LoadIPFilterConfig loads the allow and deny lists from a JSON file.
+func LoadIPFilterConfig(path string) (*IPFilterConfiguration, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+	config := &IPFilterConfiguration{}
+
+	decoder := jsoniter.NewDecoder(file)
+	err = decoder.Decode(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,144 @@
+package aero_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestIPFilter(t *testing.T) {
+	filter, err := aero.NewIPFilter(aero.IPFilterConfiguration{
+		Allow: []string{"192.0.2.0/24"},
+		Deny:  []string{"192.0.2.66"},
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, filter.Allowed("192.0.2.1"))
+	assert.False(t, filter.Allowed("192.0.2.66"))
+	assert.False(t, filter.Allowed("198.51.100.1"))
+
+	// Watching is a no-op without a file
+	stop := filter.Watch(time.Millisecond, func(err error) {
+		t.Error(err)
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	stop()
+
+	app := aero.New()
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	app.Use(filter.Middleware())
+	app.BindMiddleware()
+
+	request := httptest.NewRequest("GET", "/", nil)
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusOK)
+
+	request.RemoteAddr = "192.0.2.66:1234"
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusForbidden)
+}
+
+func TestIPFilterInternalOnly(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/admin", aero.Handler(func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	}).Bind(aero.InternalOnly()))
+
+	request := httptest.NewRequest("GET", "/admin", nil)
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusForbidden)
+
+	request.RemoteAddr = "10.0.0.5:1234"
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusOK)
+}
+
+func TestIPFilterReload(t *testing.T) {
+	directory, err := ioutil.TempDir("", "aero")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "ipfilter.json")
+	err = ioutil.WriteFile(path, []byte(`{"deny": ["198.51.100.0/24"]}`), 0644)
+	assert.Nil(t, err)
+
+	filter, err := aero.LoadIPFilter(path)
+	assert.Nil(t, err)
+	assert.False(t, filter.Allowed("198.51.100.1"))
+	assert.True(t, filter.Allowed("203.0.113.1"))
+
+	reloaded := make(chan struct{}, 1)
+	stop := filter.Watch(5*time.Millisecond, func(err error) {
+		t.Error(err)
+	})
+	defer stop()
+
+	// Modifications of the file are picked up automatically
+	err = ioutil.WriteFile(path, []byte(`{"deny": ["203.0.113.0/24"]}`), 0644)
+	assert.Nil(t, err)
+	err = os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	assert.Nil(t, err)
+
+	go func() {
+		for !filter.Allowed("198.51.100.1") {
+			time.Sleep(time.Millisecond)
+		}
+
+		reloaded <- struct{}{}
+	}()
+
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("IP filter was not reloaded")
+	}
+
+	assert.False(t, filter.Allowed("203.0.113.1"))
+}
+
+func TestIPFilterWatchBrokenFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "aero")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "ipfilter.json")
+	err = ioutil.WriteFile(path, []byte(`{"deny": ["198.51.100.0/24"]}`), 0644)
+	assert.Nil(t, err)
+
+	filter, err := aero.LoadIPFilter(path)
+	assert.Nil(t, err)
+
+	errorCount := int32(0)
+	stop := filter.Watch(2*time.Millisecond, func(err error) {
+		atomic.AddInt32(&errorCount, 1)
+	})
+	defer stop()
+
+	// A broken file is only reported once
+	err = ioutil.WriteFile(path, []byte(`{"deny": [`), 0644)
+	assert.Nil(t, err)
+	err = os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	assert.Nil(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, atomic.LoadInt32(&errorCount), int32(1))
+	assert.False(t, filter.Allowed("198.51.100.1"))
+}
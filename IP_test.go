@@ -5,6 +5,7 @@ package aero
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -55,8 +56,8 @@ func TestRealIP(t *testing.T) {
 		h := http.Header{}
 		h.Set("X-Real-IP", xRealIP)
 
-		for _, address := range xForwardedFor {
-			h.Set("X-Forwarded-For", address)
+		if len(xForwardedFor) > 0 {
+			h.Set("X-Forwarded-For", strings.Join(xForwardedFor, ", "))
 		}
 
 		return &http.Request{
@@ -65,38 +66,128 @@ func TestRealIP(t *testing.T) {
 		}
 	}
 
+	newForwardedRequest := func(remoteAddr string, forwarded ...string) *http.Request {
+		h := http.Header{}
+
+		for _, value := range forwarded {
+			h.Add("Forwarded", value)
+		}
+
+		return &http.Request{
+			RemoteAddr: remoteAddr,
+			Header:     h,
+		}
+	}
+
+	withHeader := func(request *http.Request, key string, value string) *http.Request {
+		request.Header.Set(key, value)
+		return request
+	}
+
 	// Create test data
 	publicAddr1 := "144.12.54.87"
 	publicAddr2 := "119.14.55.11"
-	localAddr := "127.0.0.0"
+	localAddr := "127.0.0.1"
+	proxyAddr := "10.0.0.1"
 
 	testData := []testIP{
 		{
 			name:     "No header",
-			request:  newRequest(publicAddr1, ""),
+			request:  newRequest(publicAddr1+":1234", ""),
+			expected: publicAddr1,
+		},
+		{
+			name:     "Spoofed X-Forwarded-For",
+			request:  newRequest(publicAddr1+":1234", "", publicAddr2),
+			expected: publicAddr1,
+		},
+		{
+			name:     "Spoofed X-Real-IP",
+			request:  newRequest(publicAddr1+":1234", publicAddr2),
 			expected: publicAddr1,
 		},
 		{
 			name:     "Has X-Forwarded-For",
-			request:  newRequest("", "", publicAddr1),
+			request:  newRequest(localAddr+":1234", "", publicAddr1),
 			expected: publicAddr1,
 		},
 		{
 			name:     "Has multiple X-Forwarded-For",
-			request:  newRequest("", "", localAddr, publicAddr1, publicAddr2),
+			request:  newRequest(localAddr+":1234", "", localAddr, publicAddr1, publicAddr2),
 			expected: publicAddr2,
 		},
+		{
+			name:     "Has trusted proxies in X-Forwarded-For",
+			request:  newRequest(localAddr+":1234", "", publicAddr1, publicAddr2, proxyAddr),
+			expected: publicAddr2,
+		},
+		{
+			name:     "Only trusted proxies in X-Forwarded-For",
+			request:  newRequest(localAddr+":1234", "", proxyAddr, localAddr),
+			expected: proxyAddr,
+		},
 		{
 			name:     "Has X-Real-IP",
-			request:  newRequest("", publicAddr1),
+			request:  newRequest(localAddr+":1234", publicAddr1),
+			expected: publicAddr1,
+		},
+		{
+			name:     "Has Forwarded",
+			request:  newForwardedRequest(localAddr+":1234", `for="[2001:db8:cafe::17]:4711";proto=https`, "for="+proxyAddr),
+			expected: "2001:db8:cafe::17",
+		},
+		{
+			name:     "Has Forwarded with unknown hop",
+			request:  newForwardedRequest(localAddr+":1234", "for=unknown, for="+proxyAddr),
+			expected: proxyAddr,
+		},
+		{
+			name:     "Forwarded takes precedence",
+			request:  withHeader(newForwardedRequest(localAddr+":1234", "for="+publicAddr1+":80"), "X-Forwarded-For", publicAddr2),
+			expected: publicAddr1,
+		},
+		{
+			name:     "Unix socket peer",
+			request:  newRequest("@", "", publicAddr1),
 			expected: publicAddr1,
 		},
 	}
 
 	// Run the test
 	for _, v := range testData {
-		if actual := realIP(v.request); v.expected != actual {
+		if actual := realIP(v.request, privateCIDRs); v.expected != actual {
 			t.Errorf("%s: expected %s but get %s", v.name, v.expected, actual)
 		}
 	}
 }
+
+func TestForwardedHeader(t *testing.T) {
+	request := &http.Request{
+		RemoteAddr: "127.0.0.1:1234",
+		Header:     http.Header{},
+	}
+
+	request.Header.Set("X-Forwarded-Proto", "https")
+	request.Header.Set("X-Forwarded-Host", "example.com")
+
+	if proto := forwardedHeader(request, privateCIDRs, "proto", "X-Forwarded-Proto"); proto != "https" {
+		t.Errorf("expected https but get %s", proto)
+	}
+
+	if host := forwardedHeader(request, privateCIDRs, "host", "X-Forwarded-Host"); host != "example.com" {
+		t.Errorf("expected example.com but get %s", host)
+	}
+
+	request.Header.Set("Forwarded", "for=144.12.54.87;proto=http;host=forwarded.example.com")
+
+	if host := forwardedHeader(request, privateCIDRs, "host", "X-Forwarded-Host"); host != "forwarded.example.com" {
+		t.Errorf("expected forwarded.example.com but get %s", host)
+	}
+
+	// Untrusted peers can't set the scheme
+	request.RemoteAddr = "144.12.54.87:1234"
+
+	if proto := forwardedHeader(request, privateCIDRs, "proto", "X-Forwarded-Proto"); proto != "" {
+		t.Errorf("expected no scheme but get %s", proto)
+	}
+}
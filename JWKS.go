@@ -0,0 +1,211 @@
+package aero
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+)
+
+// JWKS is a set of JSON web keys used to verify JWT signatures.
+type JWKS struct {
+	keys []*jsonWebKey
+}
+
+// jsonWebKey is a single parsed key of a key set.
+type jsonWebKey struct {
+	id        string
+	algorithm string
+	key       interface{}
+}
+
+// jsonWebKeyData is the JSON representation of a key.
+type jsonWebKeyData struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv"`
+	K         string `json:"k"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+// LoadJWKS loads a JSON web key set from the file system.
+func LoadJWKS(path string) (*JWKS, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(data)
+}
+
+// ParseJWKS parses a JSON web key set.
+// Symmetric keys (oct), RSA, EC (P-256, P-384, P-521)
+// and OKP (Ed25519) keys are supported.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	set := struct {
+		Keys []jsonWebKeyData `json:"keys"`
+	}{}
+
+	err := json.Unmarshal(data, &set)
+
+	if err != nil {
+		return nil, err
+	}
+
+	jwks := &JWKS{}
+
+	for _, keyData := range set.Keys {
+		// Skip encryption keys
+		if keyData.Use != "" && keyData.Use != "sig" {
+			continue
+		}
+
+		key, err := keyData.parse()
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid key '%s': %v", keyData.KeyID, err)
+		}
+
+		jwks.keys = append(jwks.keys, &jsonWebKey{
+			id:        keyData.KeyID,
+			algorithm: keyData.Algorithm,
+			key:       key,
+		})
+	}
+
+	if len(jwks.keys) == 0 {
+		return nil, errors.New("Key set doesn't contain any signature keys")
+	}
+
+	return jwks, nil
+}
+
+// parse converts the JSON representation into a Go key.
+func (keyData *jsonWebKeyData) parse() (interface{}, error) {
+	switch keyData.KeyType {
+	case "oct":
+		return decodeBase64URL(keyData.K)
+
+	case "RSA":
+		n, err := decodeBigInt(keyData.N)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(keyData.E)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("Invalid RSA exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+
+		switch keyData.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("Unsupported curve: '%s'", keyData.Curve)
+		}
+
+		x, err := decodeBigInt(keyData.X)
+
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(keyData.Y)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("Point is not on the curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if keyData.Curve != "Ed25519" {
+			return nil, fmt.Errorf("Unsupported curve: '%s'", keyData.Curve)
+		}
+
+		x, err := decodeBase64URL(keyData.X)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("Invalid Ed25519 key size")
+		}
+
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("Unsupported key type: '%s'", keyData.KeyType)
+	}
+}
+
+// candidates returns the keys that could have signed a token with the given key ID and algorithm.
+func (jwks *JWKS) candidates(keyID string, algorithm string) []*jsonWebKey {
+	var keys []*jsonWebKey
+
+	for _, key := range jwks.keys {
+		if keyID != "" && key.id != keyID {
+			continue
+		}
+
+		if key.algorithm != "" && key.algorithm != algorithm {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// decodeBase64URL decodes unpadded base64url data.
+func decodeBase64URL(data string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(data)
+}
+
+// decodeBigInt decodes an unsigned big-endian integer in base64url encoding.
+func decodeBigInt(data string) (*big.Int, error) {
+	bytes, err := decodeBase64URL(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes) == 0 {
+		return nil, errors.New("Empty integer")
+	}
+
+	return new(big.Int).SetBytes(bytes), nil
+}
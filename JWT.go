@@ -0,0 +1,314 @@
+package aero
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	// Register the hash functions used by the signature algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// JWTAuth authenticates requests via JWT bearer tokens.
+// The signature is verified with the keys of the key set and
+// the exp and nbf claims are always checked. Issuer and Audience
+// are only checked when they are set. Leeway allows for clock skew.
+// Algorithms restricts the accepted algorithms and defaults to all
+// supported ones: HS256/384/512, RS256/384/512, ES256/384/512 and EdDSA.
+type JWTAuth struct {
+	Keys       *JWKS
+	Issuer     string
+	Audience   string
+	Leeway     time.Duration
+	Algorithms []string
+}
+
+// jwtHeader is the decoded JOSE header of a token.
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Middleware returns a middleware that responds with 401 Unauthorized
+// unless the request contains a valid bearer token.
+// The sub claim and all other claims are stored as the principal of the request.
+func (auth *JWTAuth) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context) error {
+			authorization := ctx.Request().Header(authorizationHeader)
+
+			if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+				ctx.Response().SetHeader(wwwAuthenticateHeader, "Bearer")
+				return ctx.Error(http.StatusUnauthorized)
+			}
+
+			claims, err := auth.Verify(strings.TrimSpace(authorization[7:]), time.Now())
+
+			if err != nil {
+				ctx.Response().SetHeader(wwwAuthenticateHeader, `Bearer error="invalid_token"`)
+				return ctx.Error(http.StatusUnauthorized, "Invalid token", err)
+			}
+
+			subject, _ := claims["sub"].(string)
+
+			ctx.SetPrincipal(&Principal{
+				Subject: subject,
+				Scheme:  AuthBearer,
+				Claims:  claims,
+			})
+
+			return next(ctx)
+		}
+	}
+}
+
+// Verify checks the signature and the claims of the token and returns the claims.
+func (auth *JWTAuth) Verify(token string, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return nil, errors.New("Malformed token")
+	}
+
+	headerData, err := decodeBase64URL(parts[0])
+
+	if err != nil {
+		return nil, errors.New("Malformed token header")
+	}
+
+	header := jwtHeader{}
+	err = json.Unmarshal(headerData, &header)
+
+	if err != nil {
+		return nil, errors.New("Malformed token header")
+	}
+
+	if !auth.allows(header.Algorithm) {
+		return nil, fmt.Errorf("Algorithm not allowed: '%s'", header.Algorithm)
+	}
+
+	signature, err := decodeBase64URL(parts[2])
+
+	if err != nil {
+		return nil, errors.New("Malformed token signature")
+	}
+
+	if auth.Keys == nil {
+		return nil, errors.New("No keys configured")
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+
+	for _, key := range auth.Keys.candidates(header.KeyID, header.Algorithm) {
+		if verifyJWTSignature(header.Algorithm, key.key, signed, signature) {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return nil, errors.New("Invalid signature")
+	}
+
+	payload, err := decodeBase64URL(parts[1])
+
+	if err != nil {
+		return nil, errors.New("Malformed token payload")
+	}
+
+	var claims map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	err = decoder.Decode(&claims)
+
+	if err != nil {
+		return nil, errors.New("Malformed token payload")
+	}
+
+	err = auth.checkClaims(claims, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// allows reports whether the algorithm is accepted.
+func (auth *JWTAuth) allows(algorithm string) bool {
+	if _, supported := jwtHashes[algorithm]; !supported && algorithm != "EdDSA" {
+		return false
+	}
+
+	if len(auth.Algorithms) == 0 {
+		return true
+	}
+
+	for _, allowed := range auth.Algorithms {
+		if allowed == algorithm {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkClaims validates the registered time, issuer and audience claims.
+func (auth *JWTAuth) checkClaims(claims map[string]interface{}, now time.Time) error {
+	expires, exists, err := numericDate(claims, "exp")
+
+	if err != nil {
+		return err
+	}
+
+	if exists && !now.Before(expires.Add(auth.Leeway)) {
+		return errors.New("Token expired")
+	}
+
+	notBefore, exists, err := numericDate(claims, "nbf")
+
+	if err != nil {
+		return err
+	}
+
+	if exists && now.Add(auth.Leeway).Before(notBefore) {
+		return errors.New("Token not valid yet")
+	}
+
+	if auth.Issuer != "" {
+		issuer, _ := claims["iss"].(string)
+
+		if issuer != auth.Issuer {
+			return errors.New("Invalid issuer")
+		}
+	}
+
+	if auth.Audience != "" {
+		valid := false
+
+		switch audience := claims["aud"].(type) {
+		case string:
+			valid = audience == auth.Audience
+
+		case []interface{}:
+			for _, value := range audience {
+				if value == auth.Audience {
+					valid = true
+					break
+				}
+			}
+		}
+
+		if !valid {
+			return errors.New("Invalid audience")
+		}
+	}
+
+	return nil
+}
+
+// numericDate reads a claim containing seconds since the Unix epoch.
+func numericDate(claims map[string]interface{}, name string) (time.Time, bool, error) {
+	value, exists := claims[name]
+
+	if !exists {
+		return time.Time{}, false, nil
+	}
+
+	number, ok := value.(json.Number)
+
+	if !ok {
+		return time.Time{}, true, fmt.Errorf("Invalid '%s' claim", name)
+	}
+
+	seconds, err := number.Float64()
+
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("Invalid '%s' claim", name)
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), true, nil
+}
+
+// jwtHashes maps the algorithms to their hash functions.
+var jwtHashes = map[string]crypto.Hash{
+	"HS256": crypto.SHA256,
+	"HS384": crypto.SHA384,
+	"HS512": crypto.SHA512,
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// jwtCurveSizes maps the ECDSA algorithms to the byte size of their curve.
+var jwtCurveSizes = map[string]int{
+	"ES256": 32,
+	"ES384": 48,
+	"ES512": 66,
+}
+
+// verifyJWTSignature verifies the signature if the key type matches the algorithm.
+func verifyJWTSignature(algorithm string, key interface{}, signed []byte, signature []byte) bool {
+	if algorithm == "EdDSA" {
+		publicKey, ok := key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(publicKey, signed, signature)
+	}
+
+	hash := jwtHashes[algorithm]
+	hasher := hash.New()
+	_, _ = hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch algorithm[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+
+		if !ok {
+			return false
+		}
+
+		mac := hmac.New(hash.New, secret)
+		_, _ = mac.Write(signed)
+		return hmac.Equal(signature, mac.Sum(nil))
+
+	case "RS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(publicKey, hash, digest, signature) == nil
+
+	case "ES":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+
+		if !ok {
+			return false
+		}
+
+		// The curve must match the algorithm, e.g. P-256 for ES256
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+
+		if size != jwtCurveSizes[algorithm] || len(signature) != 2*size {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(publicKey, digest, r, s)
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,188 @@
+package aero_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+var b64 = base64.RawURLEncoding
+
+// testKeys contains a key pair for every supported algorithm family.
+type testKeys struct {
+	secret     []byte
+	rsa        *rsa.PrivateKey
+	ecdsa      *ecdsa.PrivateKey
+	ed25519    ed25519.PrivateKey
+	ed25519Pub ed25519.PublicKey
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	keys := &testKeys{
+		secret: []byte("a-very-secret-key-for-testing-only"),
+	}
+
+	var err error
+	keys.rsa, err = rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	keys.ecdsa, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	keys.ed25519Pub, keys.ed25519, err = ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	return keys
+}
+
+func (keys *testKeys) jwks() string {
+	return fmt.Sprintf(`{"keys": [
+		{"kty": "oct", "kid": "hs", "alg": "HS256", "k": "%s"},
+		{"kty": "RSA", "kid": "rs", "n": "%s", "e": "%s"},
+		{"kty": "EC", "kid": "es", "crv": "P-256", "x": "%s", "y": "%s"},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "%s"},
+		{"kty": "RSA", "use": "enc", "n": "", "e": ""}
+	]}`,
+		b64.EncodeToString(keys.secret),
+		b64.EncodeToString(keys.rsa.N.Bytes()),
+		b64.EncodeToString(big.NewInt(int64(keys.rsa.E)).Bytes()),
+		b64.EncodeToString(keys.ecdsa.X.Bytes()),
+		b64.EncodeToString(keys.ecdsa.Y.Bytes()),
+		b64.EncodeToString(keys.ed25519Pub),
+	)
+}
+
+func (keys *testKeys) sign(t *testing.T, algorithm string, keyID string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": algorithm, "kid": keyID, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	var err error
+
+	switch algorithm {
+	case "HS256":
+		mac := hmac.New(sha256.New, keys.secret)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, keys.rsa, crypto.SHA256, digest[:])
+	case "ES256":
+		r, s, signErr := ecdsa.Sign(rand.Reader, keys.ecdsa, digest[:])
+		err = signErr
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	case "EdDSA":
+		signature = ed25519.Sign(keys.ed25519, []byte(signed))
+	}
+
+	assert.Nil(t, err)
+	return signed + "." + b64.EncodeToString(signature)
+}
+
+func TestJWTAuth(t *testing.T) {
+	keys := newTestKeys(t)
+	jwks, err := aero.ParseJWKS([]byte(keys.jwks()))
+	assert.Nil(t, err)
+
+	auth := &aero.JWTAuth{
+		Keys:     jwks,
+		Issuer:   "https://issuer.example.com",
+		Audience: "api",
+	}
+
+	now := time.Now()
+	valid := map[string]interface{}{
+		"sub": "user-1",
+		"iss": "https://issuer.example.com",
+		"aud": []string{"other", "api"},
+		"exp": now.Add(time.Hour).Unix(),
+		"nbf": now.Add(-time.Minute).Unix(),
+	}
+
+	for _, algorithm := range []struct{ name, keyID string }{{"HS256", "hs"}, {"RS256", "rs"}, {"ES256", "es"}, {"EdDSA", "ed"}} {
+		claims, err := auth.Verify(keys.sign(t, algorithm.name, algorithm.keyID, valid), now)
+		assert.Nil(t, err)
+		assert.Equal(t, claims["sub"], "user-1")
+
+		// Signature made for a different key
+		_, err = auth.Verify(keys.sign(t, algorithm.name, "hs", valid), now)
+
+		if algorithm.keyID != "hs" {
+			assert.NotNil(t, err)
+		}
+	}
+
+	invalid := []map[string]interface{}{
+		{"sub": "user-1", "iss": "https://issuer.example.com", "aud": "api", "exp": now.Add(-time.Minute).Unix()},
+		{"sub": "user-1", "iss": "https://issuer.example.com", "aud": "api", "nbf": now.Add(time.Minute).Unix()},
+		{"sub": "user-1", "iss": "https://evil.example.com", "aud": "api"},
+		{"sub": "user-1", "iss": "https://issuer.example.com", "aud": "other"},
+		{"sub": "user-1", "iss": "https://issuer.example.com", "aud": "api", "exp": "tomorrow"},
+	}
+
+	for _, claims := range invalid {
+		_, err := auth.Verify(keys.sign(t, "ES256", "es", claims), now)
+		assert.NotNil(t, err)
+	}
+
+	// Algorithm "none" and tampered tokens
+	none := b64.EncodeToString([]byte(`{"alg":"none"}`)) + "." + b64.EncodeToString([]byte(`{"sub":"admin"}`)) + "."
+	_, err = auth.Verify(none, now)
+	assert.NotNil(t, err)
+
+	token := keys.sign(t, "RS256", "rs", valid)
+	_, err = auth.Verify(token[:len(token)-4]+"AAAA", now)
+	assert.NotNil(t, err)
+}
+
+func TestJWTAuthMiddleware(t *testing.T) {
+	keys := newTestKeys(t)
+	jwks, err := aero.ParseJWKS([]byte(keys.jwks()))
+	assert.Nil(t, err)
+
+	auth := &aero.JWTAuth{
+		Keys:       jwks,
+		Algorithms: []string{"EdDSA"},
+	}
+
+	app := aero.New()
+
+	app.Get("/", aero.Handler(func(ctx aero.Context) error {
+		return ctx.Text(ctx.Principal().Subject)
+	}).Bind(auth.Middleware()))
+
+	claims := map[string]interface{}{"sub": "user-2"}
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("Authorization", "Bearer "+keys.sign(t, "EdDSA", "ed", claims))
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Body.String(), "user-2")
+
+	// Not in the list of allowed algorithms
+	request.Header.Set("Authorization", "Bearer "+keys.sign(t, "HS256", "hs", claims))
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusUnauthorized)
+	assert.Equal(t, response.Header().Get("WWW-Authenticate"), `Bearer error="invalid_token"`)
+
+	response = test(app, "/")
+	assert.Equal(t, response.Code, http.StatusUnauthorized)
+}
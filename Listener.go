@@ -1,7 +1,13 @@
 package aero
 
 import (
+	stdContext "context"
+	"errors"
+	"fmt"
 	"net"
+	"os"
+	"strconv"
+	"syscall"
 	"time"
 )
 
@@ -47,3 +53,136 @@ func (listener Listener) Accept() (net.Conn, error) {
 
 	return connection, nil
 }
+
+// ListenerConfiguration describes a socket the server listens on.
+// Network is "tcp", "tcp4", "tcp6", "unix" or "systemd".
+// For Unix domain sockets, the address is the socket path and
+// Mode sets its file permissions, e.g. "0660". For systemd socket
+// activation, the address is the name of the file descriptor
+// (FileDescriptorName=) or empty to use the next unused one.
+// TLS serves HTTPS using the certificate of the application.
+type ListenerConfiguration struct {
+	Network string `json:"network"`
+	Address string `json:"address"`
+	TLS     bool   `json:"tls"`
+	Mode    string `json:"mode"`
+}
+
+// url returns the URL of the listener for display purposes.
+func (config *ListenerConfiguration) url(listener net.Listener) string {
+	scheme := "http"
+
+	if config.TLS {
+		scheme = "https"
+	}
+
+	address := listener.Addr()
+
+	if address.Network() != "tcp" {
+		return scheme + "+unix://" + address.String()
+	}
+
+	host, port, _ := net.SplitHostPort(address.String())
+	ip := net.ParseIP(host)
+
+	if ip == nil || ip.IsUnspecified() {
+		host = "localhost"
+	}
+
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
+
+// listen creates the listener described by the configuration.
+// TCP listeners are wrapped to set keep-alive and NoDelay.
+// With shared set, multiple processes can bind the same TCP address.
+func (config *ListenerConfiguration) listen(ctx stdContext.Context, shared bool) (net.Listener, error) {
+	var (
+		listener net.Listener
+		err      error
+	)
+
+	switch config.Network {
+	case "tcp", "tcp4", "tcp6":
+		listenConfig := net.ListenConfig{}
+
+		if shared {
+			listenConfig.Control = reusePort
+		}
+
+		listener, err = listenConfig.Listen(ctx, config.Network, config.Address)
+
+	case "unix":
+		listener, err = listenUnix(ctx, config.Address, config.Mode)
+
+	case "systemd":
+		listener, err = systemdListener(config.Address)
+
+	default:
+		return nil, fmt.Errorf("Unsupported listener network: '%s'", config.Network)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	tcpListener, isTCP := listener.(*net.TCPListener)
+
+	if isTCP {
+		return Listener{tcpListener}, nil
+	}
+
+	return listener, nil
+}
+
+// listenUnix creates a Unix domain socket with the given file mode.
+// A stale socket file left behind by a crashed process is removed,
+// but a socket that is still served by another process is kept.
+func listenUnix(ctx stdContext.Context, path string, mode string) (net.Listener, error) {
+	info, err := os.Lstat(path)
+
+	if err == nil && info.Mode()&os.ModeSocket != 0 {
+		dialer := net.Dialer{Timeout: time.Second}
+		connection, err := dialer.DialContext(ctx, "unix", path)
+
+		if err == nil {
+			connection.Close()
+			return nil, fmt.Errorf("Unix socket '%s' is already in use", path)
+		}
+
+		// Only remove the file if nobody is accepting connections
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			err = os.Remove(path)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	listenConfig := net.ListenConfig{}
+	listener, err := listenConfig.Listen(ctx, "unix", path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == "" {
+		return listener, nil
+	}
+
+	permissions, err := strconv.ParseUint(mode, 8, 32)
+
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("Invalid socket mode '%s'", mode)
+	}
+
+	err = os.Chmod(path, os.FileMode(permissions))
+
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
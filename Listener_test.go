@@ -0,0 +1,154 @@
+package aero_test
+
+import (
+	stdContext "context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestListenerUnix(t *testing.T) {
+	directory, err := ioutil.TempDir("", "aero")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "aero.sock")
+	app := aero.New()
+
+	app.Config.Listeners = []aero.ListenerConfiguration{
+		{
+			Network: "unix",
+			Address: path,
+			Mode:    "0600",
+		},
+		{
+			Network: "tcp4",
+			Address: "127.0.0.1:0",
+		},
+	}
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	assert.Nil(t, app.Start(stdContext.Background()))
+	defer app.Stop(stdContext.Background())
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0600))
+
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx stdContext.Context, network string, address string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+
+	response, err := client.Get("http://unix/")
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	assert.Equal(t, string(body), helloWorld)
+
+	addresses := app.Addresses()
+	assert.Equal(t, len(addresses), 2)
+	assert.Equal(t, addresses[0].Network(), "unix")
+	assert.Equal(t, addresses[1].(*net.TCPAddr).IP.String(), "127.0.0.1")
+
+	response, err = http.Get("http://" + addresses[1].String() + "/")
+	assert.Nil(t, err)
+	response.Body.Close()
+	assert.Equal(t, response.StatusCode, http.StatusOK)
+}
+
+func TestListenerUnixForwarded(t *testing.T) {
+	directory, err := ioutil.TempDir("", "aero")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "aero.sock")
+	app := aero.New()
+	app.Config.Listeners = []aero.ListenerConfiguration{{Network: "unix", Address: path}}
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(ctx.IP())
+	})
+
+	assert.Nil(t, app.Start(stdContext.Background()))
+	defer app.Stop(stdContext.Background())
+
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx stdContext.Context, network string, address string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+
+	// Reverse proxies on the same machine are trusted
+	request, err := http.NewRequest("GET", "http://unix/", nil)
+	assert.Nil(t, err)
+	request.Header.Set("X-Forwarded-For", "144.12.54.87")
+
+	response, err := client.Do(request)
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	assert.Equal(t, string(body), "144.12.54.87")
+}
+
+func TestListenerUnixInUse(t *testing.T) {
+	directory, err := ioutil.TempDir("", "aero")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "aero.sock")
+
+	// A stale socket file of a crashed process is replaced
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	assert.Nil(t, err)
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+
+	app := aero.New()
+	app.Config.Listeners = []aero.ListenerConfiguration{{Network: "unix", Address: path}}
+	assert.Nil(t, app.Start(stdContext.Background()))
+	defer app.Stop(stdContext.Background())
+
+	// A socket that is still served is not taken over
+	other := aero.New()
+	other.Config.Listeners = app.Config.Listeners
+	assert.NotNil(t, other.Start(stdContext.Background()))
+
+	connection, err := net.Dial("unix", path)
+	assert.Nil(t, err)
+	connection.Close()
+}
+
+func TestListenerErrors(t *testing.T) {
+	configs := []aero.ListenerConfiguration{
+		{Network: "udp", Address: ":0"},
+		{Network: "tcp", Address: ":0", TLS: true},
+		{Network: "unix", Address: filepath.Join(os.TempDir(), "aero-invalid-mode.sock"), Mode: "rw"},
+		{Network: "systemd"},
+	}
+
+	for _, config := range configs {
+		app := aero.New()
+		app.Config.Listeners = []aero.ListenerConfiguration{
+			{Network: "tcp", Address: "127.0.0.1:0"},
+			config,
+		}
+
+		assert.NotNil(t, app.Start(stdContext.Background()))
+		assert.Equal(t, len(app.Addresses()), 0)
+	}
+}
@@ -0,0 +1,303 @@
+package aero
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// contentTypeMetrics is the content type of the Prometheus text exposition format.
+const contentTypeMetrics = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	// durationBuckets are the upper bounds of the request duration histogram in seconds.
+	durationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+	// sizeBuckets are the upper bounds of the response size histogram in bytes.
+	sizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+// metrics contains the instrumentation of an application.
+// The 64-bit counters come first to guarantee their alignment
+// for atomic operations on 32-bit platforms.
+type metrics struct {
+	inFlight     int64
+	eventStreams int64
+	pushes       uint64
+	gzipInput    uint64
+	gzipOutput   uint64
+	poolGets     uint64
+	poolMisses   uint64
+	mutex        sync.RWMutex
+	requests     map[requestLabels]*requestMetrics
+}
+
+// requestLabels identifies a time series of the request metrics.
+// The route pattern is used instead of the path to limit the number of series.
+type requestLabels struct {
+	method string
+	route  string
+	status int
+}
+
+// requestMetrics are the metrics of all requests with the same labels.
+type requestMetrics struct {
+	count    uint64
+	duration *histogram
+	size     *histogram
+}
+
+// histogram counts observations in buckets with the given upper bounds.
+type histogram struct {
+	sum     uint64
+	count   uint64
+	bounds  []float64
+	buckets []uint64
+}
+
+// ServeMetrics enables the built-in instrumentation and registers
+// a route serving the metrics in the Prometheus text format.
+// The given middleware is only bound to the metrics route,
+// e.g. to restrict access via InternalOnly.
+func (app *Application) ServeMetrics(path string, middleware ...Middleware) {
+	app.metrics = &metrics{
+		requests: make(map[requestLabels]*requestMetrics),
+	}
+
+	handler := Handler(func(ctx Context) error {
+		ctx.Response().SetHeader(contentTypeHeader, contentTypeMetrics)
+		ctx.Response().SetHeader(cacheControlHeader, "no-store")
+		return ctx.Bytes(app.metrics.export())
+	})
+
+	app.Get(path, handler.Bind(middleware...))
+}
+
+// metricsMethod returns the method label for the request.
+// Clients can send arbitrary methods, therefore unknown
+// methods share a single label to keep the number of series bounded.
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// begin is called when a request starts.
+func (metrics *metrics) begin() {
+	atomic.AddInt64(&metrics.inFlight, 1)
+}
+
+// end is called when a request has finished.
+func (metrics *metrics) end(ctx *context) {
+	atomic.AddInt64(&metrics.inFlight, -1)
+
+	labels := requestLabels{
+		method: metricsMethod(ctx.request.inner.Method),
+		route:  ctx.Route(),
+		status: ctx.Status(),
+	}
+
+	metrics.mutex.RLock()
+	series := metrics.requests[labels]
+	metrics.mutex.RUnlock()
+
+	if series == nil {
+		metrics.mutex.Lock()
+		series = metrics.requests[labels]
+
+		if series == nil {
+			series = &requestMetrics{
+				duration: newHistogram(durationBuckets),
+				size:     newHistogram(sizeBuckets),
+			}
+
+			metrics.requests[labels] = series
+		}
+
+		metrics.mutex.Unlock()
+	}
+
+	atomic.AddUint64(&series.count, 1)
+	series.duration.observe(time.Since(ctx.response.writer.start).Seconds())
+	series.size.observe(float64(ctx.response.Size()))
+}
+
+// export returns the metrics in the Prometheus text exposition format.
+func (metrics *metrics) export() []byte {
+	metrics.mutex.RLock()
+	labels := make([]requestLabels, 0, len(metrics.requests))
+	series := make(map[requestLabels]*requestMetrics, len(metrics.requests))
+
+	for label, requests := range metrics.requests {
+		labels = append(labels, label)
+		series[label] = requests
+	}
+
+	metrics.mutex.RUnlock()
+
+	// Sort the series to get a stable output
+	sort.Slice(labels, func(i, j int) bool {
+		a, b := labels[i], labels[j]
+
+		if a.route != b.route {
+			return a.route < b.route
+		}
+
+		if a.method != b.method {
+			return a.method < b.method
+		}
+
+		return a.status < b.status
+	})
+
+	labelTexts := make([]string, len(labels))
+
+	for i, label := range labels {
+		labelTexts[i] = `method="` + escapeLabel(label.method) + `",route="` + escapeLabel(label.route) + `",status="` + strconv.Itoa(label.status) + `"`
+	}
+
+	buffer := bytes.Buffer{}
+
+	writeMetricHeader(&buffer, "aero_requests_total", "counter", "Total number of HTTP requests.")
+
+	for i, label := range labels {
+		writeMetric(&buffer, "aero_requests_total", labelTexts[i], float64(atomic.LoadUint64(&series[label].count)))
+	}
+
+	writeMetricHeader(&buffer, "aero_request_duration_seconds", "histogram", "Duration of HTTP requests.")
+
+	for i, label := range labels {
+		series[label].duration.write(&buffer, "aero_request_duration_seconds", labelTexts[i])
+	}
+
+	writeMetricHeader(&buffer, "aero_response_size_bytes", "histogram", "Size of HTTP response bodies.")
+
+	for i, label := range labels {
+		series[label].size.write(&buffer, "aero_response_size_bytes", labelTexts[i])
+	}
+
+	gauges := []struct {
+		name  string
+		help  string
+		value int64
+	}{
+		{"aero_requests_in_flight", "Number of requests currently being served.", atomic.LoadInt64(&metrics.inFlight)},
+		{"aero_event_streams_open", "Number of open event streams.", atomic.LoadInt64(&metrics.eventStreams)},
+	}
+
+	for _, gauge := range gauges {
+		writeMetricHeader(&buffer, gauge.name, "gauge", gauge.help)
+		writeMetric(&buffer, gauge.name, "", float64(gauge.value))
+	}
+
+	counters := []struct {
+		name  string
+		help  string
+		value uint64
+	}{
+		{"aero_pushes_total", "Total number of HTTP/2 pushes.", atomic.LoadUint64(&metrics.pushes)},
+		{"aero_gzip_input_bytes_total", "Total number of bytes before gzip compression.", atomic.LoadUint64(&metrics.gzipInput)},
+		{"aero_gzip_output_bytes_total", "Total number of bytes after gzip compression.", atomic.LoadUint64(&metrics.gzipOutput)},
+		{"aero_context_pool_gets_total", "Total number of contexts taken from the pool.", atomic.LoadUint64(&metrics.poolGets)},
+		{"aero_context_pool_misses_total", "Total number of contexts that had to be allocated because the pool was empty.", atomic.LoadUint64(&metrics.poolMisses)},
+	}
+
+	for _, counter := range counters {
+		writeMetricHeader(&buffer, counter.name, "counter", counter.help)
+		writeMetric(&buffer, counter.name, "", float64(counter.value))
+	}
+
+	return buffer.Bytes()
+}
+
+// newHistogram creates a histogram with the given bucket bounds.
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds:  bounds,
+		buckets: make([]uint64, len(bounds)),
+	}
+}
+
+// observe adds a value to the histogram.
+func (histogram *histogram) observe(value float64) {
+	index := sort.SearchFloat64s(histogram.bounds, value)
+
+	if index < len(histogram.buckets) {
+		atomic.AddUint64(&histogram.buckets[index], 1)
+	}
+
+	atomic.AddUint64(&histogram.count, 1)
+
+	for {
+		old := atomic.LoadUint64(&histogram.sum)
+		sum := math.Float64bits(math.Float64frombits(old) + value)
+
+		if atomic.CompareAndSwapUint64(&histogram.sum, old, sum) {
+			return
+		}
+	}
+}
+
+// write writes the cumulative buckets, the sum and the count of the histogram.
+func (histogram *histogram) write(buffer *bytes.Buffer, name string, labels string) {
+	cumulative := uint64(0)
+	prefix := labels
+
+	if prefix != "" {
+		prefix += ","
+	}
+
+	for i, bound := range histogram.bounds {
+		cumulative += atomic.LoadUint64(&histogram.buckets[i])
+		writeMetric(buffer, name+"_bucket", prefix+`le="`+formatFloat(bound)+`"`, float64(cumulative))
+	}
+
+	count := atomic.LoadUint64(&histogram.count)
+	writeMetric(buffer, name+"_bucket", prefix+`le="+Inf"`, float64(count))
+	writeMetric(buffer, name+"_sum", labels, math.Float64frombits(atomic.LoadUint64(&histogram.sum)))
+	writeMetric(buffer, name+"_count", labels, float64(count))
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric.
+func writeMetricHeader(buffer *bytes.Buffer, name string, kind string, help string) {
+	buffer.WriteString("# HELP " + name + " " + help + "\n")
+	buffer.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+// writeMetric writes a single sample.
+func writeMetric(buffer *bytes.Buffer, name string, labels string, value float64) {
+	buffer.WriteString(name)
+
+	if labels != "" {
+		buffer.WriteByte('{')
+		buffer.WriteString(labels)
+		buffer.WriteByte('}')
+	}
+
+	buffer.WriteByte(' ')
+	buffer.WriteString(formatFloat(value))
+	buffer.WriteByte('\n')
+}
+
+// formatFloat formats a sample value.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// labelEscaper escapes label values in the text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value.
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,91 @@
+package aero_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	app := aero.New()
+	app.ServeMetrics("/metrics")
+	streamOpen := make(chan string, 1)
+
+	app.Get("/user/:id", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	app.Get("/big", func(ctx aero.Context) error {
+		return ctx.Text(strings.Repeat(helloWorld, 1000))
+	})
+
+	app.Get("/stream", func(ctx aero.Context) error {
+		stream := aero.NewEventStream()
+
+		go func() {
+			streamOpen <- metricsText(app)
+			<-stream.Closed
+		}()
+
+		return ctx.EventStream(stream)
+	})
+
+	app.Get(`/quote"d`, func(ctx aero.Context) error {
+		return ctx.Error(http.StatusTeapot)
+	})
+
+	test(app, "/user/1")
+	test(app, "/user/2")
+	test(app, "/big")
+	test(app, "/404")
+	test(app, `/quote"d`)
+
+	// Arbitrary methods must not create new series
+	for _, method := range []string{"FOO", "BAR"} {
+		response := httptest.NewRecorder()
+		app.ServeHTTP(response, httptest.NewRequest(method, "/404", nil))
+		assert.Equal(t, response.Code, http.StatusNotFound)
+	}
+
+	request := httptest.NewRequest("GET", "/stream", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	app.ServeHTTP(httptest.NewRecorder(), request.WithContext(ctx))
+	assert.Contains(t, <-streamOpen, "aero_event_streams_open 1\n")
+
+	response := test(app, "/metrics")
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8")
+
+	text := metricsText(app)
+	assert.Contains(t, text, "# TYPE aero_requests_total counter\n")
+	assert.Contains(t, text, `aero_requests_total{method="GET",route="/user/:id",status="200"} 2`+"\n")
+	assert.Contains(t, text, `aero_requests_total{method="GET",route="",status="404"} 1`+"\n")
+	assert.Contains(t, text, `aero_requests_total{method="GET",route="/quote\"d",status="418"} 1`+"\n")
+	assert.Contains(t, text, `aero_requests_total{method="OTHER",route="",status="404"} 2`+"\n")
+	assert.NotContains(t, text, "FOO")
+	assert.NotContains(t, text, "BAR")
+	assert.Contains(t, text, "# TYPE aero_request_duration_seconds histogram\n")
+	assert.Contains(t, text, `aero_request_duration_seconds_bucket{method="GET",route="/user/:id",status="200",le="+Inf"} 2`+"\n")
+	assert.Contains(t, text, `aero_request_duration_seconds_count{method="GET",route="/user/:id",status="200"} 2`+"\n")
+	assert.Contains(t, text, `aero_response_size_bytes_bucket{method="GET",route="/user/:id",status="200",le="100"} 2`+"\n")
+	assert.Contains(t, text, `aero_response_size_bytes_sum{method="GET",route="/user/:id",status="200"} 22`+"\n")
+	assert.Contains(t, text, "aero_requests_in_flight 1\n")
+	assert.Contains(t, text, "aero_event_streams_open 0\n")
+	assert.NotContains(t, text, "aero_gzip_input_bytes_total 0\n")
+	assert.Contains(t, text, "aero_context_pool_gets_total ")
+	assert.NotContains(t, text, "aero_gzip_output_bytes_total 0\n")
+}
+
+func metricsText(app *aero.Application) string {
+	request := httptest.NewRequest("GET", "/metrics", nil)
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	return response.Body.String()
+}
@@ -0,0 +1,143 @@
+package aero
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"runtime/debug"
+
+	"github.com/akyoto/color"
+)
+
+// PanicError is passed to the OnError callbacks when a handler panics.
+// It contains the recovered value, the stack trace of the panicking
+// goroutine and the request that caused it.
+type PanicError struct {
+	Value     interface{}
+	Stack     []byte
+	Method    string
+	Path      string
+	Route     string
+	RequestID string
+}
+
+// Error returns a short description of the panic.
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("Panic in %s %s: %v", err.Method, err.Path, err.Value)
+}
+
+// Unwrap returns the recovered value if it is an error.
+func (err *PanicError) Unwrap() error {
+	cause, _ := err.Value.(error)
+	return cause
+}
+
+// recoverPanic converts a panic of the request handling into
+// a 500 response and reports it to the OnError callbacks.
+// If the headers have already been sent, the response is aborted
+// via http.ErrAbortHandler because a partial response can't be
+// turned into an error response. http.ErrAbortHandler itself is
+// passed on to net/http which will abort the response without logging it.
+func (app *Application) recoverPanic(ctx *context) {
+	value := recover()
+
+	if value == nil {
+		return
+	}
+
+	if value == http.ErrAbortHandler {
+		app.finish(ctx)
+		panic(value)
+	}
+
+	err := &PanicError{
+		Value:     value,
+		Stack:     debug.Stack(),
+		Method:    ctx.request.inner.Method,
+		Path:      ctx.Path(),
+		Route:     ctx.Route(),
+		RequestID: ctx.RequestID(),
+	}
+
+	headersSent := ctx.response.HeadersSent()
+
+	if ctx.rootSpan != nil {
+		ctx.rootSpan.RecordError(err)
+	}
+
+	if !headersSent {
+		app.renderPanic(ctx, err)
+	}
+
+	if len(app.onError) == 0 {
+		color.Red(err.Error())
+		_, _ = os.Stderr.Write(err.Stack)
+	}
+
+	for _, callback := range app.onError {
+		callback(ctx, err)
+	}
+
+	app.finish(ctx)
+
+	if headersSent {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// renderPanic responds with 500 Internal Server Error.
+// In debug mode the response contains the stack trace.
+func (app *Application) renderPanic(ctx *context, err *PanicError) {
+	header := ctx.response.inner.Header()
+	header.Del(contentEncodingHeader)
+	header.Del(contentLengthHeader)
+	header.Del(etagHeader)
+	header.Set(cacheControlHeader, "no-store")
+
+	if !app.Config.Debug {
+		httpErr := &HTTPError{Status: http.StatusInternalServerError}
+		_ = ctx.renderError(httpErr)
+		return
+	}
+
+	header.Set(contentTypeHeader, contentTypeHTML)
+	ctx.response.inner.WriteHeader(http.StatusInternalServerError)
+
+	fmt.Fprintf(
+		ctx.response.inner,
+		panicPage,
+		html.EscapeString(fmt.Sprint(err.Value)),
+		html.EscapeString(err.Method),
+		html.EscapeString(err.Path),
+		html.EscapeString(err.Route),
+		html.EscapeString(err.RequestID),
+		html.EscapeString(string(err.Stack)),
+	)
+}
+
+// panicPage is the HTML page shown for panics in debug mode.
+const panicPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>500 Internal Server Error</title>
+<style>
+body { font-family: sans-serif; margin: 2rem; color: #222; }
+h1 { color: #c00; font-size: 1.4rem; }
+td { padding: 0.2rem 1rem 0.2rem 0; }
+pre { background: #f4f4f4; padding: 1rem; overflow: auto; }
+</style>
+</head>
+<body>
+<h1>panic: %s</h1>
+<table>
+<tr><td>Method</td><td>%s</td></tr>
+<tr><td>Path</td><td>%s</td></tr>
+<tr><td>Route</td><td>%s</td></tr>
+<tr><td>Request ID</td><td>%s</td></tr>
+</table>
+<pre>%s</pre>
+</body>
+</html>
+`
@@ -0,0 +1,364 @@
+package aero
+
+import (
+	stdContext "context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// preforkWorkerEnv contains the index of a worker process.
+	preforkWorkerEnv = "AERO_PREFORK_WORKER"
+
+	// preforkRestartDelay is the time to wait before restarting a crashed worker.
+	preforkRestartDelay = time.Second
+
+	// preforkParentCheckInterval is how often workers check if the master is still alive.
+	preforkParentCheckInterval = time.Second
+)
+
+// Role describes which kind of process the application runs in.
+type Role string
+
+// Process roles.
+const (
+	// RoleStandalone is a single process serving requests.
+	RoleStandalone Role = "standalone"
+
+	// RoleMaster supervises the workers in prefork mode and doesn't serve requests.
+	RoleMaster Role = "master"
+
+	// RoleWorker is one of the processes serving requests in prefork mode.
+	RoleWorker Role = "worker"
+)
+
+// Role returns the role of the current process.
+// This lets OnStart and OnEnd callbacks distinguish
+// the prefork master from its workers.
+func (app *Application) Role() Role {
+	if os.Getenv(preforkWorkerEnv) != "" {
+		return RoleWorker
+	}
+
+	if app.Config.Prefork > 0 {
+		return RoleMaster
+	}
+
+	return RoleStandalone
+}
+
+// preforkWorker is a worker process supervised by the master.
+type preforkWorker struct {
+	index      int
+	mutex      sync.Mutex
+	process    *os.Process
+	restarting bool
+	started    chan struct{}
+}
+
+// runMaster starts the workers, restarts them when they crash
+// and stops them when a termination signal arrives or Stop is called.
+// Restart signals replace the workers one after another and reload
+// signals are forwarded to the workers.
+func (app *Application) runMaster() error {
+	if reusePort == nil {
+		return errors.New("Prefork requires SO_REUSEPORT which is not supported on this platform")
+	}
+
+	for _, config := range app.listenerConfigurations() {
+		if config.Network != "tcp" && config.Network != "tcp4" && config.Network != "tcp6" {
+			return fmt.Errorf("Prefork only supports TCP listeners, not '%s'", config.Network)
+		}
+	}
+
+	executable, err := os.Executable()
+
+	if err != nil {
+		return err
+	}
+
+	// Receive signals
+	signal.Notify(app.stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(app.stop)
+
+	if app.Config.GracefulRestart {
+		signal.Notify(app.stop, restartSignals...)
+	} else if len(app.Security.files()) > 0 {
+		signal.Notify(app.stop, reloadSignals...)
+	}
+
+	workers := make([]*preforkWorker, app.Config.Prefork)
+
+	for index := range workers {
+		workers[index] = &preforkWorker{index: index}
+	}
+
+	app.serversMutex.Lock()
+	app.workers = workers
+	app.serversMutex.Unlock()
+
+	for _, worker := range workers {
+		app.workerGroup.Add(1)
+
+		go func(worker *preforkWorker) {
+			defer app.workerGroup.Done()
+			app.superviseWorker(executable, worker)
+		}(worker)
+	}
+
+	for _, callback := range app.onStart {
+		callback()
+	}
+
+	app.waitMaster()
+	app.Shutdown()
+	<-app.done
+	return nil
+}
+
+// waitMaster blocks until a termination signal arrives
+// or the app has been stopped.
+func (app *Application) waitMaster() {
+	for {
+		select {
+		case received := <-app.stop:
+			switch {
+			case app.Config.GracefulRestart && containsSignal(restartSignals, received):
+				go app.restartWorkers()
+
+			case containsSignal(reloadSignals, received):
+				app.serversMutex.Lock()
+				workers := app.workers
+				app.serversMutex.Unlock()
+
+				for _, worker := range workers {
+					worker.signal(received)
+				}
+
+			default:
+				return
+			}
+
+		case <-app.done:
+			return
+		}
+	}
+}
+
+// restartWorkers replaces the workers one after another so that
+// the others keep serving requests, e.g. to deploy a new executable.
+func (app *Application) restartWorkers() {
+	// Only one rolling restart at a time
+	if !atomic.CompareAndSwapInt32(&app.workersRestart, 0, 1) {
+		return
+	}
+
+	defer atomic.StoreInt32(&app.workersRestart, 0)
+
+	app.serversMutex.Lock()
+	workers := app.workers
+	app.serversMutex.Unlock()
+
+	for _, worker := range workers {
+		worker.mutex.Lock()
+
+		if app.IsShuttingDown() || worker.process == nil {
+			worker.mutex.Unlock()
+			continue
+		}
+
+		started := make(chan struct{})
+		worker.restarting = true
+		worker.started = started
+		err := worker.process.Signal(syscall.SIGTERM)
+		worker.mutex.Unlock()
+
+		if err != nil {
+			app.serverError(fmt.Errorf("Worker %d can't be restarted: %v", worker.index, err))
+			continue
+		}
+
+		select {
+		case <-started:
+		case <-app.closing:
+			return
+		case <-time.After(restartTimeout):
+			app.serverError(fmt.Errorf("Worker %d didn't become ready in time", worker.index))
+		}
+	}
+}
+
+// stopWorkers asks the workers to shut down gracefully
+// and kills the ones that don't exit until the deadline.
+func (app *Application) stopWorkers(ctx stdContext.Context, workers []*preforkWorker) {
+	if len(workers) == 0 {
+		return
+	}
+
+	for _, worker := range workers {
+		worker.signal(syscall.SIGTERM)
+	}
+
+	exited := make(chan struct{})
+
+	go func() {
+		app.workerGroup.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+		return
+	case <-ctx.Done():
+	}
+
+	for _, worker := range workers {
+		worker.signal(os.Kill)
+	}
+
+	<-exited
+}
+
+// superviseWorker runs the worker process and restarts it until shutdown.
+func (app *Application) superviseWorker(executable string, worker *preforkWorker) {
+	for {
+		command := exec.Command(executable, os.Args[1:]...)
+		command.Stdin = os.Stdin
+		command.Stdout = os.Stdout
+		command.Stderr = os.Stderr
+		command.Env = append(
+			restartEnvironment(),
+			preforkWorkerEnv+"="+strconv.Itoa(worker.index),
+			readyFDEnv+"="+strconv.Itoa(inheritedFDsStart),
+		)
+
+		// The worker reports on this pipe when it's serving requests
+		reader, writer, err := os.Pipe()
+
+		if err == nil {
+			command.ExtraFiles = []*os.File{writer}
+		}
+
+		// Don't start new workers once the shutdown has begun
+		worker.mutex.Lock()
+
+		if app.IsShuttingDown() {
+			worker.mutex.Unlock()
+
+			if err == nil {
+				reader.Close()
+				writer.Close()
+			}
+
+			return
+		}
+
+		if err == nil {
+			err = command.Start()
+
+			// Close our copy of the write end so that reading
+			// fails when the worker exits before it's ready.
+			writer.Close()
+		}
+
+		if err == nil {
+			worker.process = command.Process
+		}
+
+		started := worker.started
+		worker.started = nil
+		worker.mutex.Unlock()
+
+		// Let a rolling restart continue with the next worker once this one is ready
+		go func(ready bool) {
+			if ready {
+				_, _ = reader.Read(make([]byte, 1))
+			}
+
+			if reader != nil {
+				reader.Close()
+			}
+
+			if started != nil {
+				close(started)
+			}
+		}(err == nil)
+
+		if err == nil {
+			err = command.Wait()
+		}
+
+		if app.IsShuttingDown() {
+			return
+		}
+
+		worker.mutex.Lock()
+		worker.process = nil
+		restarting := worker.restarting
+		worker.restarting = false
+		worker.mutex.Unlock()
+
+		// Workers replaced by a rolling restart are started again immediately
+		if restarting {
+			continue
+		}
+
+		if err == nil {
+			err = errors.New("exited")
+		}
+
+		app.serverError(fmt.Errorf("Worker %d failed: %v", worker.index, err))
+
+		select {
+		case <-time.After(preforkRestartDelay):
+		case <-app.closing:
+		}
+	}
+}
+
+// signal sends a signal to the worker process if it's running.
+func (worker *preforkWorker) signal(sig os.Signal) {
+	worker.mutex.Lock()
+	defer worker.mutex.Unlock()
+
+	if worker.process == nil {
+		return
+	}
+
+	err := worker.process.Signal(sig)
+
+	// Windows can't send SIGTERM
+	if err != nil && sig != os.Kill {
+		_ = worker.process.Kill()
+	}
+}
+
+// watchMaster stops the worker when the master process exits.
+func (app *Application) watchMaster() {
+	master := os.Getppid()
+
+	for {
+		select {
+		case <-app.done:
+			return
+
+		case <-time.After(preforkParentCheckInterval):
+			if os.Getppid() != master {
+				select {
+				case app.stop <- syscall.SIGTERM:
+				default:
+				}
+
+				return
+			}
+		}
+	}
+}
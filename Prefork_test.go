@@ -0,0 +1,314 @@
+//go:build !windows
+// +build !windows
+
+package aero_test
+
+import (
+	stdContext "context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+const (
+	// preforkAddressEnv passes the address to the worker processes.
+	preforkAddressEnv = "AERO_TEST_PREFORK_ADDRESS"
+
+	// preforkSlowStartEnv makes the workers wait before they listen.
+	preforkSlowStartEnv = "AERO_TEST_PREFORK_SLOW_START"
+)
+
+// runPreforkWorker serves the process ID until the master stops it.
+func runPreforkWorker() {
+	if os.Getenv(preforkSlowStartEnv) != "" {
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	app := aero.New()
+	app.Config.Listeners = []aero.ListenerConfiguration{
+		{
+			Network: "tcp4",
+			Address: os.Getenv(preforkAddressEnv),
+		},
+	}
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(strconv.Itoa(os.Getpid()) + " " + string(ctx.App().Role()))
+	})
+
+	app.Run()
+}
+
+// newPreforkApp creates a master with the given number of workers on a free port.
+func newPreforkApp(t testing.TB, workers int) (*aero.Application, string) {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := listener.Addr().String()
+	listener.Close()
+
+	os.Setenv(preforkAddressEnv, address)
+
+	app := aero.New()
+	app.Config.Prefork = workers
+	app.Config.Listeners = []aero.ListenerConfiguration{
+		{
+			Network: "tcp4",
+			Address: address,
+		},
+	}
+
+	return app, address
+}
+
+// preforkGet waits until a worker responds and returns its process ID.
+func preforkGet(t testing.TB, client *http.Client, address string) int {
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(10 * time.Millisecond) {
+		response, err := client.Get("http://" + address + "/")
+
+		if err != nil {
+			continue
+		}
+
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		fields := strings.Fields(string(body))
+
+		if len(fields) != 2 || fields[1] != string(aero.RoleWorker) {
+			t.Fatalf("Unexpected response: %s", body)
+		}
+
+		pid, _ := strconv.Atoi(fields[0])
+		return pid
+	}
+
+	t.Fatal("No worker responded")
+	return 0
+}
+
+func TestPrefork(t *testing.T) {
+	app, address := newPreforkApp(t, 2)
+	defer os.Unsetenv(preforkAddressEnv)
+
+	mutex := sync.Mutex{}
+	var serverErrors []error
+	var roles []aero.Role
+
+	app.OnServerError(func(err error) {
+		mutex.Lock()
+		serverErrors = append(serverErrors, err)
+		mutex.Unlock()
+	})
+
+	app.OnStart(func() {
+		roles = append(roles, app.Role())
+	})
+
+	app.OnShutdown(func(ctx stdContext.Context) {
+		roles = append(roles, app.Role())
+	})
+
+	done := make(chan struct{})
+
+	go func() {
+		app.Run()
+		close(done)
+	}()
+
+	client := &http.Client{
+		Transport: &http.Transport{DisableKeepAlives: true},
+		Timeout:   time.Second,
+	}
+
+	pid := preforkGet(t, client, address)
+	assert.NotEqual(t, pid, os.Getpid())
+
+	// Crashed workers are restarted
+	assert.Nil(t, syscall.Kill(pid, syscall.SIGKILL))
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		mutex.Lock()
+		crashed := len(serverErrors)
+		mutex.Unlock()
+
+		if crashed > 0 {
+			break
+		}
+	}
+
+	mutex.Lock()
+	assert.Equal(t, len(serverErrors), 1)
+	assert.Contains(t, serverErrors[0].Error(), "Worker")
+	mutex.Unlock()
+
+	time.Sleep(1500 * time.Millisecond)
+	pids := map[int]bool{}
+
+	for i := 0; i < 20; i++ {
+		pids[preforkGet(t, client, address)] = true
+	}
+
+	assert.False(t, pids[pid])
+
+	// The master stops the workers on termination
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+	<-done
+
+	assert.Equal(t, len(roles), 2)
+	assert.Equal(t, roles[0], aero.RoleMaster)
+	assert.Equal(t, roles[1], aero.RoleMaster)
+
+	_, err := client.Get("http://" + address + "/")
+	assert.NotNil(t, err)
+}
+
+func TestPreforkRestartAndStop(t *testing.T) {
+	app, address := newPreforkApp(t, 2)
+	defer os.Unsetenv(preforkAddressEnv)
+	app.Config.GracefulRestart = true
+	var serverErrors []error
+
+	app.OnServerError(func(err error) {
+		serverErrors = append(serverErrors, err)
+	})
+
+	done := make(chan struct{})
+
+	go func() {
+		app.Run()
+		close(done)
+	}()
+
+	client := &http.Client{
+		Transport: &http.Transport{DisableKeepAlives: true},
+		Timeout:   time.Second,
+	}
+
+	oldPIDs := map[int]bool{}
+
+	for i := 0; i < 20; i++ {
+		oldPIDs[preforkGet(t, client, address)] = true
+	}
+
+	// A restart signal replaces all workers without killing the master.
+	// The next worker is only replaced when the new one is listening,
+	// so the port never refuses connections during the restart.
+	os.Setenv(preforkSlowStartEnv, "true")
+	defer os.Unsetenv(preforkSlowStartEnv)
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	replaced := false
+	refused := 0
+
+	for start := time.Now(); time.Since(start) < 10*time.Second && !replaced; time.Sleep(10 * time.Millisecond) {
+		replaced = true
+
+		for i := 0; i < 10; i++ {
+			response, err := client.Get("http://" + address + "/")
+
+			if err != nil {
+				if errors.Is(err, syscall.ECONNREFUSED) {
+					refused++
+				}
+
+				replaced = false
+				break
+			}
+
+			body, _ := ioutil.ReadAll(response.Body)
+			response.Body.Close()
+			pid, _ := strconv.Atoi(strings.Fields(string(body))[0])
+
+			if oldPIDs[pid] {
+				replaced = false
+				break
+			}
+		}
+	}
+
+	assert.True(t, replaced)
+	assert.Equal(t, refused, 0)
+
+	// Stop shuts down the workers through the same path as signals
+	assert.Nil(t, app.Stop(stdContext.Background()))
+	<-done
+	assert.Equal(t, len(serverErrors), 0)
+
+	_, err := client.Get("http://" + address + "/")
+	assert.NotNil(t, err)
+}
+
+// BenchmarkPrefork compares a standalone process with prefork workers.
+// Run it with -cpu to see how the number of cores affects the results.
+func BenchmarkPrefork(b *testing.B) {
+	benchmark := func(b *testing.B, address string) {
+		client := &http.Client{
+			Transport: &http.Transport{MaxIdleConnsPerHost: 256},
+		}
+
+		preforkGet(b, &http.Client{Timeout: time.Second}, address)
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				response, err := client.Get("http://" + address + "/")
+
+				if err != nil {
+					b.Error(err)
+					return
+				}
+
+				_, _ = ioutil.ReadAll(response.Body)
+				response.Body.Close()
+			}
+		})
+	}
+
+	b.Run("Standalone", func(b *testing.B) {
+		app := aero.New()
+		app.Config.Listeners = []aero.ListenerConfiguration{{Network: "tcp4", Address: "127.0.0.1:0"}}
+
+		// Same response as the prefork workers
+		app.Get("/", func(ctx aero.Context) error {
+			return ctx.Text(strconv.Itoa(os.Getpid()) + " " + string(aero.RoleWorker))
+		})
+
+		err := app.Start(stdContext.Background())
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		defer app.Stop(stdContext.Background())
+		benchmark(b, app.Addresses()[0].String())
+	})
+
+	b.Run("Prefork", func(b *testing.B) {
+		app, address := newPreforkApp(b, 4)
+		defer os.Unsetenv(preforkAddressEnv)
+		done := make(chan struct{})
+
+		go func() {
+			app.Run()
+			close(done)
+		}()
+
+		benchmark(b, address)
+		_ = app.Stop(stdContext.Background())
+		<-done
+	})
+}
@@ -0,0 +1,16 @@
+package aero
+
+// Authentication schemes used by the built-in authentication middleware.
+const (
+	AuthBasic  = "basic"
+	AuthAPIKey = "apikey"
+	AuthBearer = "bearer"
+)
+
+// Principal is the authenticated identity of a request.
+// Claims are only set for JWT bearer tokens.
+type Principal struct {
+	Subject string
+	Scheme  string
+	Claims  map[string]interface{}
+}
@@ -0,0 +1,139 @@
+package aero
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Rate limit algorithms.
+const (
+	RateLimitTokenBucket   = "token-bucket"
+	RateLimitSlidingWindow = "sliding-window"
+)
+
+// Rate limit keys.
+const (
+	RateLimitByIP      = "ip"
+	RateLimitBySession = "session"
+	RateLimitByRoute   = "route"
+)
+
+// RateLimit describes how many requests are allowed in a given period.
+// Burst is only used by the token bucket algorithm and defaults to Requests.
+// Key decides who shares the limit: each IP (default), each session or
+// all clients of a route.
+type RateLimit struct {
+	Name      string   `json:"name"`
+	Requests  int      `json:"requests"`
+	Period    Duration `json:"period"`
+	Burst     int      `json:"burst"`
+	Algorithm string   `json:"algorithm"`
+	Key       string   `json:"key"`
+}
+
+// capacity returns the maximum number of requests that can happen at once.
+func (limit *RateLimit) capacity() int {
+	if limit.Burst > 0 {
+		return limit.Burst
+	}
+
+	return limit.Requests
+}
+
+// RateLimiter applies a rate limit to the routes it is bound to.
+// Routes sharing the same limiter also share their counters.
+type RateLimiter struct {
+	Limit   RateLimit
+	Backend RateLimitBackend
+}
+
+// NewRateLimiter creates a new rate limiter with an in-memory backend.
+// The name of the limit is used as a prefix for the backend keys.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	return &RateLimiter{
+		Limit:   limit,
+		Backend: NewMemoryRateLimitBackend(),
+	}
+}
+
+// RateLimiter returns a rate limiter using the limit with the
+// given name from the configuration and the application's backend.
+func (app *Application) RateLimiter(name string) *RateLimiter {
+	for _, limit := range app.Config.RateLimits {
+		if limit.Name == name {
+			return &RateLimiter{
+				Limit:   limit,
+				Backend: app.RateLimitBackend,
+			}
+		}
+	}
+
+	panic(fmt.Errorf("Unknown rate limit: '%s'", name))
+}
+
+// Middleware returns a middleware that responds with 429 Too Many Requests
+// when the limit is exceeded. Use it with app.Use to limit all routes
+// or bind it to a group of routes.
+// If the backend fails, requests are allowed and the error is
+// passed to the OnError callbacks.
+func (limiter *RateLimiter) Middleware() Middleware {
+	if limiter.Limit.Requests <= 0 || limiter.Limit.Period.Duration <= 0 {
+		panic(fmt.Errorf("Invalid rate limit '%s': requests and period must be positive", limiter.Limit.Name))
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx Context) error {
+			result, err := limiter.Backend.Take(limiter.key(ctx), &limiter.Limit, time.Now())
+
+			if err != nil {
+				for _, callback := range ctx.App().onError {
+					callback(ctx, err)
+				}
+
+				return next(ctx)
+			}
+
+			response := ctx.Response()
+			response.SetHeader(rateLimitLimitHeader, strconv.Itoa(result.Limit))
+			response.SetHeader(rateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+			response.SetHeader(rateLimitResetHeader, ceilSeconds(result.Reset))
+
+			if !result.Allowed {
+				response.SetHeader(retryAfterHeader, ceilSeconds(result.RetryAfter))
+				return ctx.Error(http.StatusTooManyRequests)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// key returns the backend key for the request.
+func (limiter *RateLimiter) key(ctx Context) string {
+	switch limiter.Limit.Key {
+	case RateLimitBySession:
+		if ctx.HasSession() {
+			return limiter.Limit.Name + ":session:" + ctx.Session().ID()
+		}
+
+		return limiter.Limit.Name + ":ip:" + ctx.IP()
+
+	case RateLimitByRoute:
+		return limiter.Limit.Name + ":route:" + ctx.Request().Method() + " " + ctx.Route()
+
+	default:
+		return limiter.Limit.Name + ":ip:" + ctx.IP()
+	}
+}
+
+// ceilSeconds formats the duration as a number of whole seconds, rounded up.
+func ceilSeconds(duration time.Duration) string {
+	if duration < 0 {
+		duration = 0
+	}
+
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
@@ -0,0 +1,192 @@
+package aero
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/akyoto/hash"
+)
+
+const (
+	// rateLimitShards is the number of independently locked
+	// shards in the in-memory rate limit backend.
+	rateLimitShards = 64
+
+	// rateLimitSweepSize is the number of entries per shard
+	// after which expired entries are removed.
+	rateLimitSweepSize = 1024
+)
+
+// RateLimitBackend stores the rate limit state for each key.
+// Implementations must be safe for concurrent use.
+type RateLimitBackend interface {
+	Take(key string, limit *RateLimit, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimitResult is the outcome of a single request against a rate limit.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// MemoryRateLimitBackend keeps the rate limit state in memory.
+// The keys are distributed over multiple shards to reduce lock contention.
+type MemoryRateLimitBackend struct {
+	shards [rateLimitShards]rateLimitShard
+}
+
+// rateLimitShard is a locked subset of the rate limit state.
+type rateLimitShard struct {
+	mutex     sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+}
+
+// rateLimitEntry stores the state of both algorithms for a single key.
+type rateLimitEntry struct {
+	tokens      float64
+	last        time.Time
+	windowStart time.Time
+	previous    int
+	current     int
+	expires     time.Time
+}
+
+// NewMemoryRateLimitBackend creates a new in-memory rate limit backend.
+func NewMemoryRateLimitBackend() *MemoryRateLimitBackend {
+	backend := &MemoryRateLimitBackend{}
+
+	for i := range backend.shards {
+		backend.shards[i].entries = make(map[string]*rateLimitEntry)
+	}
+
+	return backend
+}
+
+// Take counts a request for the given key and reports whether it is allowed.
+func (backend *MemoryRateLimitBackend) Take(key string, limit *RateLimit, now time.Time) (RateLimitResult, error) {
+	shard := &backend.shards[hash.String(key)%rateLimitShards]
+
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	shard.sweep(now)
+	entry := shard.entries[key]
+
+	if entry == nil {
+		entry = &rateLimitEntry{
+			tokens:      float64(limit.capacity()),
+			last:        now,
+			windowStart: now.Truncate(limit.Period.Duration),
+		}
+
+		shard.entries[key] = entry
+	}
+
+	var result RateLimitResult
+
+	switch limit.Algorithm {
+	case RateLimitSlidingWindow:
+		result = entry.slidingWindow(limit, now)
+	default:
+		result = entry.tokenBucket(limit, now)
+	}
+
+	entry.expires = now.Add(2 * limit.Period.Duration)
+	return result, nil
+}
+
+// sweep removes expired entries when the shard gets too large.
+func (shard *rateLimitShard) sweep(now time.Time) {
+	if len(shard.entries) < rateLimitSweepSize || now.Sub(shard.lastSweep) < time.Second {
+		return
+	}
+
+	for key, entry := range shard.entries {
+		if now.After(entry.expires) {
+			delete(shard.entries, key)
+		}
+	}
+
+	shard.lastSweep = now
+}
+
+// tokenBucket refills the bucket at a constant rate and takes one token per request.
+func (entry *rateLimitEntry) tokenBucket(limit *RateLimit, now time.Time) RateLimitResult {
+	capacity := float64(limit.capacity())
+	rate := float64(limit.Requests) / limit.Period.Seconds()
+
+	entry.tokens = math.Min(capacity, entry.tokens+now.Sub(entry.last).Seconds()*rate)
+	entry.last = now
+
+	result := RateLimitResult{
+		Limit: limit.capacity(),
+	}
+
+	if entry.tokens >= 1 {
+		entry.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - entry.tokens) / rate)
+	}
+
+	result.Remaining = int(entry.tokens)
+	result.Reset = seconds((capacity - entry.tokens) / rate)
+	return result
+}
+
+// slidingWindow estimates the request count of the last period using
+// the counters of the current and the previous fixed window.
+func (entry *rateLimitEntry) slidingWindow(limit *RateLimit, now time.Time) RateLimitResult {
+	period := limit.Period.Duration
+	windowStart := now.Truncate(period)
+
+	if !windowStart.Equal(entry.windowStart) {
+		if windowStart.Sub(entry.windowStart) == period {
+			entry.previous = entry.current
+		} else {
+			entry.previous = 0
+		}
+
+		entry.current = 0
+		entry.windowStart = windowStart
+	}
+
+	elapsed := now.Sub(windowStart)
+	weight := 1 - elapsed.Seconds()/period.Seconds()
+	estimate := float64(entry.previous)*weight + float64(entry.current)
+
+	result := RateLimitResult{
+		Limit: limit.Requests,
+		Reset: period - elapsed,
+	}
+
+	if estimate+1 <= float64(limit.Requests) {
+		entry.current++
+		estimate++
+		result.Allowed = true
+	} else if entry.current+1 > limit.Requests || entry.previous == 0 {
+		result.RetryAfter = period - elapsed
+	} else {
+		// Time at which the weighted previous window has decayed enough
+		needed := 1 - float64(limit.Requests-1-entry.current)/float64(entry.previous)
+		result.RetryAfter = time.Duration(needed*float64(period)) - elapsed
+	}
+
+	result.Remaining = limit.Requests - int(math.Ceil(estimate))
+
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+
+	return result
+}
+
+// seconds converts floating point seconds to a duration.
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,91 @@
+package aero_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	app := aero.New()
+
+	limiter := aero.NewRateLimiter(aero.RateLimit{
+		Name:     "test",
+		Requests: 3,
+		Period:   aero.Duration{Duration: time.Minute},
+	})
+
+	app.Get("/", aero.Handler(func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	}).Bind(limiter.Middleware()))
+
+	for i := 0; i < 3; i++ {
+		response := test(app, "/")
+		assert.Equal(t, response.Code, http.StatusOK)
+		assert.Equal(t, response.Header().Get("RateLimit-Limit"), "3")
+		assert.Equal(t, response.Header().Get("Retry-After"), "")
+	}
+
+	response := test(app, "/")
+	assert.Equal(t, response.Code, http.StatusTooManyRequests)
+	assert.Equal(t, response.Header().Get("RateLimit-Remaining"), "0")
+	assert.Equal(t, response.Header().Get("Retry-After"), "20")
+}
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	backend := aero.NewMemoryRateLimitBackend()
+	limit := &aero.RateLimit{
+		Requests:  10,
+		Period:    aero.Duration{Duration: time.Minute},
+		Algorithm: aero.RateLimitSlidingWindow,
+	}
+
+	start := time.Now().Truncate(time.Minute)
+
+	for i := 0; i < 10; i++ {
+		result, err := backend.Take("key", limit, start)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	result, _ := backend.Take("key", limit, start.Add(30*time.Second))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, result.RetryAfter, 30*time.Second)
+
+	// Halfway through the next window, half of the previous requests still count
+	next := start.Add(90 * time.Second)
+
+	for i := 0; i < 5; i++ {
+		result, _ = backend.Take("key", limit, next)
+		assert.True(t, result.Allowed)
+	}
+
+	result, _ = backend.Take("key", limit, next)
+	assert.False(t, result.Allowed)
+
+	// Other keys are not affected
+	result, _ = backend.Take("other", limit, next)
+	assert.True(t, result.Allowed)
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	config, err := aero.LoadConfig("testdata/config.json")
+	assert.Nil(t, err)
+
+	app := aero.New()
+	app.Config = config
+
+	limiter := app.RateLimiter("api")
+	assert.Equal(t, limiter.Limit.Requests, 100)
+	assert.Equal(t, limiter.Limit.Period.Duration, time.Minute)
+	assert.Equal(t, limiter.Limit.Algorithm, aero.RateLimitSlidingWindow)
+
+	defer func() {
+		assert.NotNil(t, recover())
+	}()
+
+	app.RateLimiter("unknown")
+}
@@ -3,6 +3,7 @@ package aero
 import (
 	stdContext "context"
 	"net/http"
+	"strings"
 )
 
 // Request is an interface for HTTP requests.
@@ -20,7 +21,9 @@ type Request interface {
 
 // request represents the HTTP request used in the given context.
 type request struct {
-	inner *http.Request
+	inner  *http.Request
+	app    *Application
+	values stdContext.Context
 }
 
 // Body represents the request body.
@@ -31,8 +34,13 @@ func (req *request) Body() Body {
 }
 
 // Context returns the request context.
+// Values stored via ctx.Set are visible in the returned context.
 func (req *request) Context() stdContext.Context {
-	return req.inner.Context()
+	if req.values == nil {
+		return req.inner.Context()
+	}
+
+	return req.values
 }
 
 // Header returns the header value for the given key.
@@ -51,7 +59,15 @@ func (req *request) Protocol() string {
 }
 
 // Host returns the requested host.
+// The Forwarded and X-Forwarded-Host headers are
+// only respected when they were set by a trusted proxy.
 func (req *request) Host() string {
+	host := req.forwarded("host", forwardedHostHeader)
+
+	if host != "" {
+		return host
+	}
+
 	return req.inner.Host
 }
 
@@ -61,11 +77,13 @@ func (req *request) Path() string {
 }
 
 // Scheme returns http or https depending on what scheme has been used.
+// The Forwarded and X-Forwarded-Proto headers are
+// only respected when they were set by a trusted proxy.
 func (req *request) Scheme() string {
-	scheme := req.inner.Header.Get("X-Forwarded-Proto")
+	scheme := req.forwarded("proto", forwardedProtoHeader)
 
 	if scheme != "" {
-		return scheme
+		return strings.ToLower(scheme)
 	}
 
 	if req.inner.TLS != nil {
@@ -75,6 +93,15 @@ func (req *request) Scheme() string {
 	return "http"
 }
 
+// forwarded returns the value of a forwarding header if the peer is a trusted proxy.
+func (req *request) forwarded(key string, legacyHeader string) string {
+	if req.app == nil {
+		return ""
+	}
+
+	return forwardedHeader(req.inner, req.app.trustedProxies, key, legacyHeader)
+}
+
 // Internal returns the underlying *http.Request.
 // This method should be avoided unless absolutely necessary
 // because Aero doesn't guarantee that the underlying framework
@@ -0,0 +1,195 @@
+package aero
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// maxRequestIDLength is the maximum length of an incoming request ID.
+const maxRequestIDLength = 128
+
+// requestTrace identifies the request across services.
+// The trace ID and flags are only set when the request
+// is part of a W3C trace context. The span ID is only
+// set when a tracer is configured.
+type requestTrace struct {
+	id         string
+	traceID    string
+	traceFlags string
+	spanID     string
+}
+
+// RequestID returns a middleware that assigns an ID to every request.
+// The ID is taken from a valid X-Request-Id header, the trace ID of a
+// W3C traceparent header or newly generated, in that order.
+// It is available via ctx.RequestID() and echoed in the X-Request-Id
+// response header. Use NewClient to pass it on to other services.
+func RequestID() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context) error {
+			request := ctx.Request()
+			internal, isInternal := ctx.(*context)
+			trace := requestTrace{}
+
+			// Keep the trace started by the tracer
+			if isInternal {
+				trace = internal.trace
+			}
+
+			if trace.traceID == "" {
+				if traceID, _, traceFlags, ok := parseTraceParent(request.Header(traceParentHeader)); ok {
+					trace.traceID = traceID
+					trace.traceFlags = traceFlags
+				}
+			}
+
+			trace.id = request.Header(requestIDHeader)
+
+			if !isValidRequestID(trace.id) {
+				trace.id = trace.traceID
+			}
+
+			if trace.id == "" {
+				trace.id = randomHex(16)
+
+				if trace.traceID == "" {
+					trace.traceID = trace.id
+					trace.traceFlags = "00"
+				}
+			}
+
+			if isInternal {
+				internal.trace = trace
+			}
+
+			ctx.Response().SetHeader(requestIDHeader, trace.id)
+			return next(ctx)
+		}
+	}
+}
+
+// NewClient returns an HTTP client that adds the request ID
+// and the W3C trace context of the given request to all requests it sends.
+// With a tracer, the current span is used as the parent of the outgoing requests.
+func NewClient(ctx Context) *http.Client {
+	trace := requestTrace{id: ctx.RequestID()}
+
+	if internal, ok := ctx.(*context); ok {
+		trace = internal.trace
+
+		if internal.span != nil {
+			trace.spanID = internal.span.Context().SpanID
+		}
+	}
+
+	return &http.Client{
+		Transport: &requestIDTransport{
+			base:  http.DefaultTransport,
+			trace: trace,
+		},
+	}
+}
+
+// requestIDTransport adds the request ID headers to outgoing requests.
+type requestIDTransport struct {
+	base  http.RoundTripper
+	trace requestTrace
+}
+
+// RoundTrip sends the request with the request ID headers.
+func (transport *requestIDTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	trace := &transport.trace
+
+	if trace.id == "" && trace.traceID == "" {
+		return transport.base.RoundTrip(request)
+	}
+
+	// A RoundTripper must not modify the original request
+	request = request.Clone(request.Context())
+
+	if trace.id != "" {
+		request.Header.Set(requestIDHeader, trace.id)
+	}
+
+	if trace.traceID != "" {
+		parentID := trace.spanID
+
+		if parentID == "" {
+			parentID = randomHex(8)
+		}
+
+		request.Header.Set(traceParentHeader, "00-"+trace.traceID+"-"+parentID+"-"+trace.traceFlags)
+	}
+
+	return transport.base.RoundTrip(request)
+}
+
+// parseTraceParent returns the trace ID, the parent ID and the flags of a W3C traceparent header.
+func parseTraceParent(header string) (string, string, string, bool) {
+	// version-traceid-parentid-flags, e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	if len(header) < 55 || header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return "", "", "", false
+	}
+
+	version := header[:2]
+	traceID := header[3:35]
+	parentID := header[36:52]
+	flags := header[53:55]
+
+	// Version 00 has a fixed length, future versions may append fields.
+	if version == "ff" || (version == "00" && len(header) != 55) || (len(header) > 55 && header[55] != '-') {
+		return "", "", "", false
+	}
+
+	if !isLowerHex(version) || !isLowerHex(traceID) || !isLowerHex(parentID) || !isLowerHex(flags) {
+		return "", "", "", false
+	}
+
+	if traceID == "00000000000000000000000000000000" || parentID == "0000000000000000" {
+		return "", "", "", false
+	}
+
+	return traceID, parentID, flags, true
+}
+
+// isValidRequestID checks that an incoming request ID
+// is safe to be written to logs and response headers.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '/', c == '+', c == '=':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isLowerHex checks that the string only contains lowercase hex digits.
+func isLowerHex(text string) bool {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// randomHex returns the hex encoding of n random bytes.
+func randomHex(n int) string {
+	data := make([]byte, n)
+	_, _ = rand.Read(data)
+	return hex.EncodeToString(data)
+}
@@ -0,0 +1,135 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	app := aero.New()
+	app.Use(aero.RequestID())
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(ctx.RequestID())
+	})
+
+	app.BindMiddleware()
+
+	// Generated
+	response := test(app, "/")
+	generated := response.Body.String()
+	assert.True(t, regexp.MustCompile("^[0-9a-f]{32}$").MatchString(generated))
+	assert.Equal(t, response.Header().Get("X-Request-Id"), generated)
+	assert.NotEqual(t, test(app, "/").Body.String(), generated)
+
+	headers := []struct {
+		requestID   string
+		traceParent string
+		expected    string
+	}{
+		{"abc-123", "", "abc-123"},
+		{"abc-123", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "abc-123"},
+		{"", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"evil\nlog line", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{strings.Repeat("a", 200), "", ""},
+		{"", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+		{"", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", ""},
+		{"", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", "4bf92f3577b34da6a3ce929d0e0e4736"},
+	}
+
+	for _, header := range headers {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("X-Request-Id", header.requestID)
+		request.Header.Set("traceparent", header.traceParent)
+		response := httptest.NewRecorder()
+		app.ServeHTTP(response, request)
+
+		if header.expected == "" {
+			assert.NotEqual(t, response.Body.String(), header.requestID)
+			assert.Equal(t, len(response.Body.String()), 32)
+		} else {
+			assert.Equal(t, response.Body.String(), header.expected)
+		}
+
+		assert.Equal(t, response.Header().Get("X-Request-Id"), response.Body.String())
+	}
+}
+
+func TestRequestIDClient(t *testing.T) {
+	var received http.Header
+
+	backend := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		received = request.Header
+	}))
+
+	defer backend.Close()
+
+	app := aero.New()
+	app.Use(aero.RequestID())
+
+	app.Get("/", func(ctx aero.Context) error {
+		request, _ := http.NewRequest("GET", backend.URL, nil)
+		response, err := aero.NewClient(ctx).Do(request)
+
+		if err != nil {
+			return err
+		}
+
+		response.Body.Close()
+		assert.Equal(t, request.Header.Get("X-Request-Id"), "")
+		return ctx.Text(ctx.RequestID())
+	})
+
+	app.BindMiddleware()
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("X-Request-Id", "abc-123")
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, received.Get("X-Request-Id"), "abc-123")
+
+	traceParent := received.Get("traceparent")
+	assert.True(t, regexp.MustCompile("^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$").MatchString(traceParent))
+	assert.NotEqual(t, traceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Generated IDs start a new trace
+	response = test(app, "/")
+	id := response.Body.String()
+	assert.Equal(t, received.Get("X-Request-Id"), id)
+	assert.True(t, strings.HasPrefix(received.Get("traceparent"), "00-"+id+"-"))
+}
+
+func TestRequestIDPanic(t *testing.T) {
+	app := aero.New()
+	app.Use(aero.RequestID())
+	var reported *aero.PanicError
+
+	app.Get("/", func(ctx aero.Context) error {
+		panic("oops")
+	})
+
+	app.OnError(func(ctx aero.Context, err error) {
+		reported = err.(*aero.PanicError)
+	})
+
+	app.BindMiddleware()
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("X-Request-Id", "abc-123")
+	request.Header.Set("Accept", "application/problem+json")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+
+	assert.Equal(t, response.Code, http.StatusInternalServerError)
+	assert.Equal(t, reported.RequestID, "abc-123")
+	assert.Contains(t, response.Body.String(), `"requestId":"abc-123"`)
+}
@@ -2,6 +2,7 @@ package aero_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -41,3 +42,56 @@ func TestMultiRequest(t *testing.T) {
 		test(app, "/")
 	}
 }
+
+func TestRequestTrustedProxy(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/", func(ctx aero.Context) error {
+		request := ctx.Request()
+		return ctx.Text(request.Scheme() + "://" + request.Host() + " " + ctx.IP())
+	})
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("X-Forwarded-Proto", "https")
+	request.Header.Set("X-Forwarded-Host", "example.org")
+	request.Header.Set("X-Forwarded-For", "144.12.54.87")
+
+	// httptest requests come from 192.0.2.1 which is not trusted by default
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Body.String(), "http://example.com 192.0.2.1")
+
+	err := app.TrustProxies("192.0.2.0/24")
+	assert.Nil(t, err)
+
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Body.String(), "https://example.org 144.12.54.87")
+
+	err = app.TrustProxies("invalid")
+	assert.NotNil(t, err)
+}
+
+func TestRequestPrivateNetworkNotTrusted(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(ctx.Request().Scheme() + " " + ctx.IP())
+	})
+
+	// Clients on a shared private network can't spoof forwarding headers by default
+	request := httptest.NewRequest("GET", "/", nil)
+	request.RemoteAddr = "10.0.0.5:1234"
+	request.Header.Set("X-Forwarded-Proto", "https")
+	request.Header.Set("X-Forwarded-For", "144.12.54.87")
+
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Body.String(), "http 10.0.0.5")
+
+	// Loopback proxies are trusted
+	request.RemoteAddr = "127.0.0.1:1234"
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Body.String(), "https 144.12.54.87")
+}
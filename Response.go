@@ -2,19 +2,27 @@ package aero
 
 import (
 	"net/http"
+	"time"
 )
 
 // Response is the interface for an HTTP response.
 type Response interface {
 	Header(string) string
+	HeadersSent() bool
 	Internal() http.ResponseWriter
 	SetHeader(string, string)
 	SetInternal(http.ResponseWriter)
+	Size() int64
+	Status() int
+	TimeToFirstByte() time.Duration
 }
 
 // response represents the HTTP response used in the given context.
+// The inner writer wraps the writer of net/http unless
+// it has been replaced via SetInternal.
 type response struct {
-	inner http.ResponseWriter
+	inner  http.ResponseWriter
+	writer responseWriter
 }
 
 // Header returns the header value for the given key.
@@ -27,6 +35,30 @@ func (res *response) SetHeader(key string, value string) {
 	res.inner.Header().Set(key, value)
 }
 
+// HeadersSent indicates whether the status and the headers have been sent.
+// Headers can't be modified after this point.
+func (res *response) HeadersSent() bool {
+	return res.writer.status != 0
+}
+
+// Size returns the number of body bytes that have been written.
+// Compressed responses report the compressed size.
+func (res *response) Size() int64 {
+	return res.writer.bytes
+}
+
+// Status returns the status code that has been sent or 0 if
+// the headers haven't been sent yet.
+func (res *response) Status() int {
+	return res.writer.status
+}
+
+// TimeToFirstByte returns the time from the start of the
+// request until the headers have been sent.
+func (res *response) TimeToFirstByte() time.Duration {
+	return res.writer.firstByte
+}
+
 // Internal returns the underlying http.ResponseWriter.
 // This method should be avoided unless absolutely necessary
 // because Aero doesn't guarantee that the underlying framework
@@ -0,0 +1,122 @@
+package aero
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// responseWriter wraps the http.ResponseWriter of every request.
+// It tracks the status that was actually sent, the number of bytes
+// written and the time to the first byte. It preserves the
+// Flusher, Pusher and Hijacker interfaces.
+type responseWriter struct {
+	http.ResponseWriter
+	status    int
+	bytes     int64
+	start     time.Time
+	firstByte time.Duration
+
+	// beforeSend is called once right before the headers are sent.
+	beforeSend func()
+}
+
+// reset prepares the writer for a new request.
+func (writer *responseWriter) reset(inner http.ResponseWriter, start time.Time) {
+	writer.ResponseWriter = inner
+	writer.status = 0
+	writer.bytes = 0
+	writer.start = start
+	writer.firstByte = 0
+}
+
+// sent records the status when the headers are sent.
+func (writer *responseWriter) sent(status int) {
+	if writer.status != 0 {
+		return
+	}
+
+	if writer.beforeSend != nil {
+		writer.beforeSend()
+	}
+
+	writer.status = status
+	writer.firstByte = time.Since(writer.start)
+}
+
+// WriteHeader sends the status code.
+// Informational 1xx responses are not recorded as the final status.
+func (writer *responseWriter) WriteHeader(status int) {
+	if status >= 200 {
+		writer.sent(status)
+	}
+
+	writer.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the data and implicitly sends 200 OK if no status was sent.
+func (writer *responseWriter) Write(data []byte) (int, error) {
+	writer.sent(http.StatusOK)
+	n, err := writer.ResponseWriter.Write(data)
+	writer.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends any buffered data to the client.
+func (writer *responseWriter) Flush() {
+	flusher, ok := writer.ResponseWriter.(http.Flusher)
+
+	if !ok {
+		return
+	}
+
+	writer.sent(http.StatusOK)
+	flusher.Flush()
+}
+
+// Push initiates an HTTP/2 server push.
+func (writer *responseWriter) Push(target string, options *http.PushOptions) error {
+	pusher, ok := writer.ResponseWriter.(http.Pusher)
+
+	if !ok {
+		return http.ErrNotSupported
+	}
+
+	return pusher.Push(target, options)
+}
+
+// Hijack lets the caller take over the connection.
+func (writer *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := writer.ResponseWriter.(http.Hijacker)
+
+	if !ok {
+		return nil, nil, errors.New("Hijacking not supported")
+	}
+
+	connection, buffer, err := hijacker.Hijack()
+
+	if err == nil {
+		writer.sent(http.StatusSwitchingProtocols)
+	}
+
+	return connection, buffer, err
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (writer *responseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// unwrapResponseWriter returns the writer underneath the tracking wrapper.
+// This is needed to check which optional interfaces are really supported.
+func unwrapResponseWriter(writer http.ResponseWriter) http.ResponseWriter {
+	wrapper, ok := writer.(*responseWriter)
+
+	if ok {
+		return wrapper.ResponseWriter
+	}
+
+	return writer
+}
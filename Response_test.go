@@ -0,0 +1,132 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestResponseTracking(t *testing.T) {
+	app := aero.New()
+
+	type result struct {
+		status      int
+		size        int64
+		headersSent bool
+		ttfb        time.Duration
+	}
+
+	var observed result
+
+	app.Use(func(next aero.Handler) aero.Handler {
+		return func(ctx aero.Context) error {
+			assert.False(t, ctx.Response().HeadersSent())
+			err := next(ctx)
+
+			observed = result{
+				status:      ctx.Status(),
+				size:        ctx.Response().Size(),
+				headersSent: ctx.Response().HeadersSent(),
+				ttfb:        ctx.Response().TimeToFirstByte(),
+			}
+
+			return err
+		}
+	})
+
+	app.Get("/text", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	app.Get("/file", func(ctx aero.Context) error {
+		return ctx.File("testdata/config.json")
+	})
+
+	app.Get("/missing", func(ctx aero.Context) error {
+		return ctx.File("testdata/does-not-exist.json")
+	})
+
+	app.Get("/reader", func(ctx aero.Context) error {
+		return ctx.ReadSeeker(strings.NewReader(helloWorld))
+	})
+
+	app.Get("/nothing", func(ctx aero.Context) error {
+		ctx.SetStatus(http.StatusAccepted)
+		return nil
+	})
+
+	app.BindMiddleware()
+
+	response := test(app, "/text")
+	assert.Equal(t, observed.status, http.StatusOK)
+	assert.Equal(t, observed.size, int64(len(helloWorld)))
+	assert.True(t, observed.headersSent)
+	assert.True(t, observed.ttfb > 0)
+
+	response = test(app, "/file")
+	assert.Equal(t, observed.status, http.StatusOK)
+	assert.Equal(t, observed.size, int64(response.Body.Len()))
+
+	// Conditional request answered by http.ServeFile
+	request := httptest.NewRequest("GET", "/file", nil)
+	request.Header.Set("If-Modified-Since", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusNotModified)
+	assert.Equal(t, observed.status, http.StatusNotModified)
+	assert.Equal(t, observed.size, int64(0))
+
+	test(app, "/missing")
+	assert.Equal(t, observed.status, http.StatusNotFound)
+
+	request = httptest.NewRequest("GET", "/reader", nil)
+	request.Header.Set("Range", "bytes=0-4")
+	response = httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, observed.status, http.StatusPartialContent)
+	assert.Equal(t, observed.size, int64(5))
+
+	test(app, "/nothing")
+	assert.Equal(t, observed.status, http.StatusAccepted)
+	assert.False(t, observed.headersSent)
+	assert.Equal(t, observed.ttfb, time.Duration(0))
+}
+
+func TestResponsePanicAfterHeaders(t *testing.T) {
+	app := aero.New()
+	var reported error
+
+	app.Get("/", func(ctx aero.Context) error {
+		_ = ctx.Text(helloWorld)
+		panic("too late")
+	})
+
+	app.OnError(func(ctx aero.Context, err error) {
+		reported = err
+	})
+
+	defer func() {
+		assert.Equal(t, recover(), http.ErrAbortHandler)
+		assert.NotNil(t, reported)
+	}()
+
+	test(app, "/")
+}
+
+func TestResponseReturnedErrorAfterHeaders(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/", func(ctx aero.Context) error {
+		_ = ctx.Text(helloWorld)
+		return &aero.HTTPError{Status: http.StatusBadGateway}
+	})
+
+	response := test(app, "/")
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Body.String(), helloWorld)
+}
@@ -0,0 +1,265 @@
+package aero
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// listenFDsEnv tells a restarted process how many listeners it inherited.
+	listenFDsEnv = "AERO_LISTEN_FDS"
+
+	// readyFDEnv is the file descriptor a restarted process reports readiness on.
+	readyFDEnv = "AERO_READY_FD"
+
+	// inheritedFDsStart is the first file descriptor passed via ExtraFiles.
+	inheritedFDsStart = 3
+
+	// restartTimeout is the time the new process has to become ready.
+	restartTimeout = time.Minute
+)
+
+// errRestartInterrupted is returned when the app was told
+// to terminate while waiting for the new process.
+var errRestartInterrupted = errors.New("Restart interrupted by termination")
+
+var (
+	inheritedFiles     []*os.File
+	inheritedReadyFile *os.File
+	inheritedFilesOnce sync.Once
+)
+
+// restart starts a new instance of the executable that takes over
+// the listening sockets. It returns when the new process is ready
+// to serve requests, after which the caller should shut down.
+func (app *Application) restart() error {
+	app.serversMutex.Lock()
+	listeners := app.listeners
+	app.serversMutex.Unlock()
+
+	files := make([]*os.File, 0, len(listeners)+1)
+
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
+	for _, listener := range listeners {
+		fileListener, ok := listener.(interface{ File() (*os.File, error) })
+
+		if !ok {
+			return fmt.Errorf("Listener on %s can't be passed to a new process", listener.Addr())
+		}
+
+		file, err := fileListener.File()
+
+		if err != nil {
+			return err
+		}
+
+		files = append(files, file)
+	}
+
+	reader, writer, err := os.Pipe()
+
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+	files = append(files, writer)
+
+	executable, err := os.Executable()
+
+	if err != nil {
+		return err
+	}
+
+	command := exec.Command(executable, os.Args[1:]...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	command.ExtraFiles = files
+	command.Env = append(
+		restartEnvironment(),
+		listenFDsEnv+"="+strconv.Itoa(len(listeners)),
+		readyFDEnv+"="+strconv.Itoa(inheritedFDsStart+len(listeners)),
+	)
+
+	err = command.Start()
+
+	if err != nil {
+		return err
+	}
+
+	// Close our copy of the write end so that reading
+	// fails when the new process exits before it's ready.
+	writer.Close()
+	files = files[:len(files)-1]
+
+	ready := make(chan error, 1)
+
+	go func() {
+		_, err := reader.Read(make([]byte, 1))
+		ready <- err
+	}()
+
+	err = app.waitReady(command, ready)
+
+	if err != nil {
+		return err
+	}
+
+	// The new process owns the Unix socket files from now on
+	for _, listener := range listeners {
+		unixListener, ok := listener.(*net.UnixListener)
+
+		if ok {
+			unixListener.SetUnlinkOnClose(false)
+		}
+	}
+
+	return command.Process.Release()
+}
+
+// waitReady waits until the new process reports that it's ready.
+// Termination signals and Stop are still handled while waiting,
+// in which case the new process is killed.
+func (app *Application) waitReady(command *exec.Cmd, ready <-chan error) error {
+	timeout := time.NewTimer(restartTimeout)
+	defer timeout.Stop()
+
+	abort := func() {
+		_ = command.Process.Kill()
+		_ = command.Wait()
+	}
+
+	for {
+		select {
+		case err := <-ready:
+			if err != nil {
+				_ = command.Wait()
+				return fmt.Errorf("New process exited before it was ready: %v", err)
+			}
+
+			return nil
+
+		case received := <-app.stop:
+			// Repeated restart or reload signals are ignored
+			if containsSignal(restartSignals, received) || containsSignal(reloadSignals, received) {
+				continue
+			}
+
+			abort()
+			return errRestartInterrupted
+
+		case <-app.done:
+			abort()
+			return errRestartInterrupted
+
+		case <-timeout.C:
+			abort()
+			return errors.New("New process didn't become ready in time")
+		}
+	}
+}
+
+// restartEnvironment returns the environment without the
+// variables of a previous restart.
+func restartEnvironment() []string {
+	environment := os.Environ()
+	filtered := environment[:0]
+
+	for _, variable := range environment {
+		if strings.HasPrefix(variable, listenFDsEnv+"=") || strings.HasPrefix(variable, readyFDEnv+"=") {
+			continue
+		}
+
+		filtered = append(filtered, variable)
+	}
+
+	return filtered
+}
+
+// inheritedListener returns the listener at the given index
+// that was passed on by the previous process, if any.
+func inheritedListener(index int, count int) (net.Listener, error) {
+	inheritedFilesOnce.Do(loadInheritedFiles)
+
+	if inheritedFiles == nil {
+		return nil, nil
+	}
+
+	if len(inheritedFiles) != count {
+		return nil, fmt.Errorf("Inherited %d listeners but %d are configured", len(inheritedFiles), count)
+	}
+
+	file := inheritedFiles[index]
+
+	if file == nil {
+		return nil, errors.New("Inherited listener has already been used")
+	}
+
+	listener, err := net.FileListener(file)
+	file.Close()
+	inheritedFiles[index] = nil
+
+	if err != nil {
+		return nil, err
+	}
+
+	tcpListener, isTCP := listener.(*net.TCPListener)
+
+	if isTCP {
+		return Listener{tcpListener}, nil
+	}
+
+	return listener, nil
+}
+
+// loadInheritedFiles reads the file descriptors passed by restart
+// or by the prefork master, which only passes the ready pipe.
+// The variables are removed so that child processes don't use them.
+func loadInheritedFiles() {
+	readyFD, err := strconv.Atoi(os.Getenv(readyFDEnv))
+
+	if err == nil {
+		inheritedReadyFile = os.NewFile(uintptr(readyFD), "ready")
+	}
+
+	count, err := strconv.Atoi(os.Getenv(listenFDsEnv))
+	os.Unsetenv(listenFDsEnv)
+	os.Unsetenv(readyFDEnv)
+
+	if err != nil || count <= 0 {
+		return
+	}
+
+	inheritedFiles = make([]*os.File, count)
+
+	for i := range inheritedFiles {
+		inheritedFiles[i] = os.NewFile(uintptr(inheritedFDsStart+i), "listener")
+	}
+}
+
+// notifyReady tells the previous process that it can shut down
+// or the prefork master that the worker is serving requests.
+func notifyReady() {
+	inheritedFilesOnce.Do(loadInheritedFiles)
+
+	if inheritedReadyFile == nil {
+		return
+	}
+
+	_, _ = inheritedReadyFile.Write([]byte{1})
+	inheritedReadyFile.Close()
+	inheritedReadyFile = nil
+}
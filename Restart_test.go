@@ -0,0 +1,118 @@
+//go:build !windows
+// +build !windows
+
+package aero_test
+
+import (
+	stdContext "context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+// TestMain runs the restarted process or the prefork
+// workers when the test binary is re-executed.
+func TestMain(m *testing.M) {
+	switch {
+	case os.Getenv("AERO_LISTEN_FDS") != "" && os.Getenv("AERO_RESTART_HANG") != "":
+		select {}
+
+	case os.Getenv("AERO_LISTEN_FDS") != "":
+		runRestartedProcess()
+		os.Exit(0)
+
+	case os.Getenv("AERO_PREFORK_WORKER") != "":
+		runPreforkWorker()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runRestartedProcess serves requests on the inherited listener for a while.
+func runRestartedProcess() {
+	app := aero.New()
+	app.Config.Listeners = restartListeners
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text("new")
+	})
+
+	err := app.Start(stdContext.Background())
+
+	if err != nil {
+		panic(err)
+	}
+
+	time.Sleep(time.Second)
+	_ = app.Stop(stdContext.Background())
+}
+
+var restartListeners = []aero.ListenerConfiguration{
+	{
+		Network: "tcp4",
+		Address: "127.0.0.1:0",
+	},
+}
+
+func TestApplicationRestart(t *testing.T) {
+	app := aero.New()
+	app.Config.Listeners = restartListeners
+	app.Config.GracefulRestart = true
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text("old")
+	})
+
+	app.OnStart(func() {
+		go func() {
+			assert.Equal(t, get(t, app), "old")
+			assert.Nil(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+		}()
+	})
+
+	app.Run()
+
+	// The new process serves on the same address
+	assert.Equal(t, get(t, app), "new")
+}
+
+func TestApplicationRestartInterrupted(t *testing.T) {
+	// The new process never becomes ready
+	os.Setenv("AERO_RESTART_HANG", "1")
+	defer os.Unsetenv("AERO_RESTART_HANG")
+
+	app := aero.New()
+	app.Config.Listeners = restartListeners
+	app.Config.GracefulRestart = true
+
+	app.OnStart(func() {
+		go func() {
+			assert.Nil(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+			time.Sleep(200 * time.Millisecond)
+			assert.Nil(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+		}()
+	})
+
+	// Termination is handled while waiting for the new process
+	start := time.Now()
+	app.Run()
+	assert.True(t, time.Since(start) < 5*time.Second)
+	assert.True(t, app.IsShuttingDown())
+}
+
+// get requests the front page of the app.
+func get(t *testing.T, app *aero.Application) string {
+	response, err := http.Get("http://" + app.Addresses()[0].String() + "/")
+	assert.Nil(t, err)
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	assert.Nil(t, err)
+	return string(body)
+}
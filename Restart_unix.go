@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package aero
+
+import (
+	"os"
+	"syscall"
+)
+
+// restartSignals trigger a graceful restart.
+var restartSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
+
+// reloadSignals reload the TLS certificates.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
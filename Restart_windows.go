@@ -0,0 +1,10 @@
+package aero
+
+import "os"
+
+// restartSignals trigger a graceful restart.
+// Windows doesn't support passing listeners to a new process.
+var restartSignals []os.Signal
+
+// reloadSignals reload the TLS certificates.
+var reloadSignals []os.Signal
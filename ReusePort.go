@@ -0,0 +1,37 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package aero
+
+import (
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// reusePort sets SO_REUSEPORT so that multiple processes
+// can listen on the same port and the kernel distributes
+// the incoming connections between them.
+var reusePort = func(network string, address string, conn syscall.RawConn) error {
+	var err error
+
+	controlErr := conn.Control(func(fd uintptr) {
+		err = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, soReusePort(), 1)
+	})
+
+	if controlErr != nil {
+		return controlErr
+	}
+
+	return err
+}
+
+// soReusePort returns the value of the SO_REUSEPORT option
+// which the syscall package doesn't define on every platform.
+func soReusePort() int {
+	if runtime.GOOS == "linux" && !strings.HasPrefix(runtime.GOARCH, "mips") {
+		return 0xf
+	}
+
+	return 0x200
+}
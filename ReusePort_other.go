@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package aero
+
+import "syscall"
+
+// reusePort is nil because SO_REUSEPORT is not supported on this platform.
+var reusePort func(network string, address string, conn syscall.RawConn) error
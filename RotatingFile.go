@@ -0,0 +1,200 @@
+package aero
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// rotatingFileTimeFormat is appended to the names of rotated files.
+// It sorts in chronological order.
+const rotatingFileTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// RotatingFile is an io.Writer that appends to a file and rotates it
+// once it exceeds MaxSize bytes. Rotated files are renamed with a
+// timestamp suffix and only the newest MaxBackups files are kept.
+// A MaxSize of 0 disables size-based rotation and a MaxBackups
+// of 0 keeps all rotated files.
+type RotatingFile struct {
+	MaxSize    int64
+	MaxBackups int
+
+	path   string
+	file   *os.File
+	size   int64
+	closed bool
+	mutex  sync.Mutex
+}
+
+// NewRotatingFile opens the file for appending and creates it if needed.
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	rotating := &RotatingFile{
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		path:       path,
+	}
+
+	err := rotating.open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rotating, nil
+}
+
+// Write appends the data to the file and rotates it when it gets too large.
+// If the rotation fails, writing continues in the current file and
+// the rotation is retried with the next write. If the file couldn't
+// be reopened, the next write tries to open it again.
+func (rotating *RotatingFile) Write(data []byte) (int, error) {
+	rotating.mutex.Lock()
+	defer rotating.mutex.Unlock()
+
+	if rotating.closed {
+		return 0, os.ErrClosed
+	}
+
+	if rotating.file == nil {
+		err := rotating.open()
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if rotating.MaxSize > 0 && rotating.size > 0 && rotating.size+int64(len(data)) > rotating.MaxSize {
+		err := rotating.rotate()
+
+		if rotating.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := rotating.file.Write(data)
+	rotating.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, renames it and starts a new one.
+// This can be used for time-based rotation or in a signal handler.
+func (rotating *RotatingFile) Rotate() error {
+	rotating.mutex.Lock()
+	defer rotating.mutex.Unlock()
+
+	if rotating.closed {
+		return os.ErrClosed
+	}
+
+	if rotating.file == nil {
+		err := rotating.open()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return rotating.rotate()
+}
+
+// Close closes the file.
+func (rotating *RotatingFile) Close() error {
+	rotating.mutex.Lock()
+	defer rotating.mutex.Unlock()
+
+	rotating.closed = true
+
+	if rotating.file == nil {
+		return nil
+	}
+
+	err := rotating.file.Close()
+	rotating.file = nil
+	return err
+}
+
+// open opens the file for appending.
+func (rotating *RotatingFile) open() error {
+	file, err := os.OpenFile(rotating.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	stat, err := file.Stat()
+
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	rotating.file = file
+	rotating.size = stat.Size()
+	return nil
+}
+
+// rotate renames the current file and opens a new one.
+// If the file can't be renamed, the current file is reopened.
+// The caller must hold the mutex.
+func (rotating *RotatingFile) rotate() error {
+	err := rotating.file.Close()
+	rotating.file = nil
+
+	if err != nil {
+		_ = rotating.open()
+		return err
+	}
+
+	backup := rotating.path + "." + time.Now().Format(rotatingFileTimeFormat)
+	err = os.Rename(rotating.path, backup)
+
+	if err != nil {
+		_ = rotating.open()
+		return err
+	}
+
+	err = rotating.open()
+
+	if err != nil {
+		return err
+	}
+
+	rotating.removeOldBackups()
+	return nil
+}
+
+// removeOldBackups deletes the oldest rotated files exceeding MaxBackups.
+func (rotating *RotatingFile) removeOldBackups() {
+	if rotating.MaxBackups <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(rotating.path + ".*")
+
+	if err != nil {
+		return
+	}
+
+	// Only consider files that were created by the rotation
+	backups := matches[:0]
+
+	for _, match := range matches {
+		_, err := time.Parse(rotatingFileTimeFormat, match[len(rotating.path)+1:])
+
+		if err == nil {
+			backups = append(backups, match)
+		}
+	}
+
+	if len(backups) <= rotating.MaxBackups {
+		return
+	}
+
+	sort.Strings(backups)
+
+	for _, backup := range backups[:len(backups)-rotating.MaxBackups] {
+		_ = os.Remove(backup)
+	}
+}
@@ -0,0 +1,86 @@
+package aero_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "aero-rotating-file")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "access.log")
+	assert.Nil(t, ioutil.WriteFile(path+".gz", nil, 0644))
+
+	file, err := aero.NewRotatingFile(path, 10, 2)
+	assert.Nil(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err = file.Write([]byte("12345678\n"))
+		assert.Nil(t, err)
+	}
+
+	assert.Nil(t, file.Close())
+
+	// The current file and 2 backups, unrelated files are kept
+	files, err := filepath.Glob(path + "*")
+	assert.Nil(t, err)
+	assert.Equal(t, len(files), 4)
+	assert.Contains(t, files, path+".gz")
+
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), "12345678\n")
+
+	// Manual rotation
+	file, err = aero.NewRotatingFile(path, 0, 0)
+	assert.Nil(t, err)
+	_, err = file.Write([]byte(strings.Repeat("x", 100)))
+	assert.Nil(t, err)
+	assert.Nil(t, file.Rotate())
+	assert.Nil(t, file.Close())
+
+	files, _ = filepath.Glob(path + "*")
+	assert.Equal(t, len(files), 5)
+
+	_, err = file.Write([]byte("closed"))
+	assert.NotNil(t, err)
+}
+
+func TestRotatingFileRecovery(t *testing.T) {
+	directory, err := ioutil.TempDir("", "aero-rotating-file")
+	assert.Nil(t, err)
+	defer os.RemoveAll(directory)
+
+	logs := filepath.Join(directory, "logs")
+	assert.Nil(t, os.Mkdir(logs, 0755))
+	path := filepath.Join(logs, "access.log")
+
+	file, err := aero.NewRotatingFile(path, 10, 0)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	_, err = file.Write([]byte("12345678\n"))
+	assert.Nil(t, err)
+
+	// The rotation fails while the directory is missing
+	assert.Nil(t, os.RemoveAll(logs))
+	_, err = file.Write([]byte("12345678\n"))
+	assert.NotNil(t, err)
+
+	// Writing recovers once the directory is back
+	assert.Nil(t, os.Mkdir(logs, 0755))
+	_, err = file.Write([]byte("recovered\n"))
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), "recovered\n")
+}
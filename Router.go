@@ -6,6 +6,13 @@ import (
 	"os"
 )
 
+// route is the data stored in the router for each path.
+type route struct {
+	pattern string
+	handler Handler
+	bound   bool
+}
+
 // Router is a high-performance router.
 type Router struct {
 	get     tree
@@ -27,7 +34,10 @@ func (router *Router) Add(method string, path string, handler Handler) {
 		panic(fmt.Errorf("Unknown HTTP method: '%s'", method))
 	}
 
-	tree.add(path, handler)
+	tree.add(path, &route{
+		pattern: path,
+		handler: handler,
+	})
 }
 
 // Find returns the handler for the given route.
@@ -36,23 +46,62 @@ func (router *Router) Add(method string, path string, handler Handler) {
 func (router *Router) Find(method string, path string) Handler {
 	c := context{}
 	router.Lookup(method, path, &c)
-	return c.handler
+
+	if c.route == nil {
+		return nil
+	}
+
+	return c.route.handler
 }
 
-// Lookup finds the handler and parameters for the given route
+// Lookup finds the route and parameters for the given route
 // and assigns them to the given context.
 func (router *Router) Lookup(method string, path string, ctx *context) {
 	tree := router.selectTree(method)
 
+	// Methods without a tree never have a route
+	if tree == nil {
+		ctx.route = nil
+		return
+	}
+
 	// Fast path for the root node
 	if tree.prefix == path {
-		ctx.handler = tree.data
+		ctx.route = tree.data
 		return
 	}
 
 	tree.find(path, ctx)
 }
 
+// allowedMethods returns the methods that have a route for the given path.
+func (router *Router) allowedMethods(path string) []string {
+	var methods []string
+	ctx := context{}
+
+	for _, method := range [...]string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodDelete,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodHead,
+		http.MethodConnect,
+		http.MethodTrace,
+		http.MethodOptions,
+	} {
+		ctx.route = nil
+		ctx.paramCount = 0
+		router.Lookup(method, path, &ctx)
+
+		if ctx.route != nil {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods
+}
+
 // Each traverses all trees and calls the given function on every node.
 func (router *Router) Each(callback func(*tree)) {
 	router.get.each(callback)
@@ -69,6 +118,11 @@ func (router *Router) Each(callback func(*tree)) {
 // Print shows a pretty print of the routes.
 func (router *Router) Print(method string) {
 	tree := router.selectTree(method)
+
+	if tree == nil {
+		return
+	}
+
 	tree.PrettyPrint(os.Stdout)
 }
 
@@ -6,18 +6,47 @@ import (
 	"github.com/aerogo/http/ciphers"
 )
 
-// ApplicationSecurity stores the certificate data.
+// ApplicationSecurity stores the certificate data
+// and the security headers sent with every response.
 type ApplicationSecurity struct {
 	Certificate string
 	Key         string
+	Headers     SecurityHeaders
+
+	additional []certificateFiles
 }
 
 // Load expects the path of the certificate and the key.
+// It's used for host names that no other certificate is valid for.
 func (security *ApplicationSecurity) Load(certificate string, key string) {
 	security.Certificate = certificate
 	security.Key = key
 }
 
+// AddCertificate adds a certificate for additional host names.
+// During the TLS handshake, the certificate is selected via SNI
+// based on the host names it's valid for.
+func (security *ApplicationSecurity) AddCertificate(certificate string, key string) {
+	security.additional = append(security.additional, certificateFiles{
+		certificate: certificate,
+		key:         key,
+	})
+}
+
+// files returns the paths of all certificates.
+func (security *ApplicationSecurity) files() []certificateFiles {
+	var files []certificateFiles
+
+	if security.Certificate != "" && security.Key != "" {
+		files = append(files, certificateFiles{
+			certificate: security.Certificate,
+			key:         security.Key,
+		})
+	}
+
+	return append(files, security.additional...)
+}
+
 // createTLSConfig creates a secure TLS configuration.
 func createTLSConfig() *tls.Config {
 	return &tls.Config{
@@ -0,0 +1,127 @@
+package aero
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SecurityHeaders configures the security related headers that are sent
+// with every response, regardless of the content type.
+// Empty values disable the corresponding header.
+type SecurityHeaders struct {
+	ContentTypeOptions        string
+	XSSProtection             string
+	ReferrerPolicy            string
+	CrossOriginOpenerPolicy   string
+	CrossOriginEmbedderPolicy string
+	CrossOriginResourcePolicy string
+	PermissionsPolicy         string
+	HSTS                      HSTS
+}
+
+// HSTS configures the Strict-Transport-Security header.
+// A zero MaxAge disables the header. It's only sent with HTTPS
+// responses because browsers ignore it on insecure connections.
+type HSTS struct {
+	MaxAge            time.Duration
+	IncludeSubDomains bool
+	Preload           bool
+}
+
+// DefaultSecurityHeaders returns the security headers used by new applications.
+func DefaultSecurityHeaders() SecurityHeaders {
+	return SecurityHeaders{
+		ContentTypeOptions: contentTypeOptions,
+		XSSProtection:      xssProtection,
+		ReferrerPolicy:     referrerPolicySameOrigin,
+		HSTS: HSTS{
+			MaxAge:            365 * 24 * time.Hour,
+			IncludeSubDomains: true,
+			Preload:           true,
+		},
+	}
+}
+
+// String returns the Strict-Transport-Security header value.
+func (hsts *HSTS) String() string {
+	if hsts.MaxAge <= 0 {
+		return ""
+	}
+
+	value := "max-age=" + strconv.FormatInt(int64(hsts.MaxAge/time.Second), 10)
+
+	if hsts.IncludeSubDomains {
+		value += "; includeSubDomains"
+	}
+
+	if hsts.Preload {
+		value += "; preload"
+	}
+
+	return value
+}
+
+// Middleware returns a middleware that replaces the application-wide
+// security headers with this policy. Bind it to the routes that need
+// a different policy, e.g. pages that are allowed to be embedded.
+func (headers *SecurityHeaders) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context) error {
+			headers.apply(ctx.Response().Internal().Header(), true, ctx.Request().Scheme() == "https")
+			return next(ctx)
+		}
+	}
+}
+
+// apply writes the headers to the given response header.
+// If override is true, headers with empty values are removed.
+// HSTS is only written if the connection is secure.
+func (headers *SecurityHeaders) apply(header http.Header, override bool, secure bool) {
+	hsts := ""
+
+	if secure {
+		hsts = headers.HSTS.String()
+	}
+
+	setHeader(header, contentTypeOptionsHeader, headers.ContentTypeOptions, override)
+	setHeader(header, xssProtectionHeader, headers.XSSProtection, override)
+	setHeader(header, referrerPolicyHeader, headers.ReferrerPolicy, override)
+	setHeader(header, crossOriginOpenerPolicyHeader, headers.CrossOriginOpenerPolicy, override)
+	setHeader(header, crossOriginEmbedderPolicyHeader, headers.CrossOriginEmbedderPolicy, override)
+	setHeader(header, crossOriginResourcePolicyHeader, headers.CrossOriginResourcePolicy, override)
+	setHeader(header, permissionsPolicyHeader, headers.PermissionsPolicy, override)
+	setHeader(header, strictTransportSecurityHeader, hsts, override)
+}
+
+// setHeader sets the header if the value is not empty.
+// If override is true, an empty value removes the header.
+func setHeader(header http.Header, key string, value string, override bool) {
+	if value != "" {
+		header.Set(key, value)
+		return
+	}
+
+	if override {
+		header.Del(key)
+	}
+}
+
+// applySecurityHeaders sets the application-wide security headers
+// including the content security policy on the response.
+func (app *Application) applySecurityHeaders(ctx *context) {
+	header := ctx.response.inner.Header()
+	app.Security.Headers.apply(header, false, ctx.request.Scheme() == "https")
+
+	if app.ContentSecurityPolicy != nil {
+		setHeader(header, contentSecurityPolicyHeader, app.ContentSecurityPolicy.String(), false)
+	}
+
+	if app.ContentSecurityPolicyReportOnly != nil {
+		setHeader(header, contentSecurityPolicyROHeader, app.ContentSecurityPolicyReportOnly.String(), false)
+	}
+
+	if app.cspReportPath != "" {
+		header.Set(reportingEndpointsHeader, cspReportGroup+`="`+app.cspReportPath+`"`)
+	}
+}
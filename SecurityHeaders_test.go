@@ -0,0 +1,82 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	app := aero.New()
+	app.Security.Headers.CrossOriginOpenerPolicy = "same-origin"
+	app.Security.Headers.PermissionsPolicy = "geolocation=()"
+
+	app.Get("/json", func(ctx aero.Context) error {
+		return ctx.JSON(helloWorld)
+	})
+
+	for _, route := range []string{"/json", "/404"} {
+		response := testHTTPS(app, route)
+		header := response.Header()
+
+		assert.Equal(t, header.Get("X-Content-Type-Options"), "nosniff")
+		assert.Equal(t, header.Get("Referrer-Policy"), "no-referrer")
+		assert.Equal(t, header.Get("Strict-Transport-Security"), "max-age=31536000; includeSubDomains; preload")
+		assert.Equal(t, header.Get("Cross-Origin-Opener-Policy"), "same-origin")
+		assert.Equal(t, header.Get("Permissions-Policy"), "geolocation=()")
+		assert.Equal(t, header.Get("Cross-Origin-Embedder-Policy"), "")
+		assert.Contains(t, header.Get("Content-Security-Policy"), "default-src 'none';")
+	}
+
+	// HSTS is only sent via HTTPS
+	response := test(app, "/json")
+	assert.Equal(t, response.Header().Get("Strict-Transport-Security"), "")
+	assert.Equal(t, response.Header().Get("X-Content-Type-Options"), "nosniff")
+}
+
+func TestSecurityHeadersOverride(t *testing.T) {
+	app := aero.New()
+
+	embeddable := app.Security.Headers
+	embeddable.ReferrerPolicy = "origin"
+	embeddable.CrossOriginResourcePolicy = "cross-origin"
+	embeddable.HSTS = aero.HSTS{MaxAge: time.Hour}
+	embeddable.XSSProtection = ""
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	})
+
+	app.Get("/widget", aero.Handler(func(ctx aero.Context) error {
+		return ctx.Text(helloWorld)
+	}).Bind(embeddable.Middleware()))
+
+	response := testHTTPS(app, "/widget")
+	header := response.Header()
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, header.Get("Referrer-Policy"), "origin")
+	assert.Equal(t, header.Get("Cross-Origin-Resource-Policy"), "cross-origin")
+	assert.Equal(t, header.Get("Strict-Transport-Security"), "max-age=3600")
+	assert.Equal(t, header.Get("X-XSS-Protection"), "")
+
+	// Other routes are not affected
+	response = testHTTPS(app, "/")
+	assert.Equal(t, response.Header().Get("Referrer-Policy"), "no-referrer")
+	assert.Equal(t, response.Header().Get("X-XSS-Protection"), "1; mode=block")
+	assert.Equal(t, response.Header().Get("Strict-Transport-Security"), "max-age=31536000; includeSubDomains; preload")
+
+	response = test(app, "/widget")
+	assert.Equal(t, response.Header().Get("Strict-Transport-Security"), "")
+}
+
+// testHTTPS sends a request via TLS to the server and returns the response.
+func testHTTPS(app *aero.Application, route string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest("GET", "https://example.com"+route, nil)
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	return response
+}
@@ -0,0 +1,101 @@
+package aero
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTimings defines the maximum number of Server-Timing entries per context.
+const maxTimings = 16
+
+// serverTiming is a single entry of the Server-Timing header.
+type serverTiming struct {
+	name        string
+	duration    time.Duration
+	description string
+}
+
+// Timing adds an entry to the Server-Timing header of the response.
+// The name must be a valid HTTP token. Entries are ignored if
+// server timing is disabled or the headers have already been sent.
+func (ctx *context) Timing(name string, duration time.Duration, description string) {
+	if !ctx.app.Config.ServerTiming || ctx.timingCount == maxTimings || ctx.response.HeadersSent() {
+		return
+	}
+
+	ctx.timings[ctx.timingCount] = serverTiming{
+		name:        name,
+		duration:    duration,
+		description: description,
+	}
+
+	ctx.timingCount++
+}
+
+// writeServerTiming serializes the timing entries into the
+// Server-Timing header right before the headers are sent.
+func (ctx *context) writeServerTiming() {
+	if !ctx.app.Config.ServerTiming {
+		return
+	}
+
+	if !ctx.handlerStart.IsZero() {
+		ctx.Timing("handler", time.Since(ctx.handlerStart), "")
+	}
+
+	if ctx.timingCount == 0 {
+		return
+	}
+
+	header := strings.Builder{}
+
+	for i := 0; i < ctx.timingCount; i++ {
+		timing := &ctx.timings[i]
+
+		if i > 0 {
+			header.WriteString(", ")
+		}
+
+		header.WriteString(timing.name)
+		header.WriteString(";dur=")
+		header.WriteString(strconv.FormatFloat(float64(timing.duration)/float64(time.Millisecond), 'f', 3, 64))
+
+		if timing.description != "" {
+			header.WriteString(`;desc="`)
+
+			for _, c := range timing.description {
+				if c == '"' || c == '\\' {
+					header.WriteByte('\\')
+				}
+
+				header.WriteRune(c)
+			}
+
+			header.WriteByte('"')
+		}
+	}
+
+	ctx.response.writer.Header().Add(serverTimingHeader, header.String())
+}
+
+// timedGZip compresses the body into a buffer first and
+// adds the compression time to the Server-Timing header.
+func (ctx *context) timedGZip(body []byte) error {
+	start := time.Now()
+	buffer := bytes.Buffer{}
+	writer := ctx.app.acquireGZipWriter(&buffer)
+	_, err := writer.Write(body)
+	writer.Close()
+	ctx.app.gzipWriterPool.Put(writer)
+
+	if err != nil {
+		return err
+	}
+
+	ctx.Timing("gzip", time.Since(start), "")
+	ctx.response.inner.WriteHeader(ctx.status)
+	_, err = ctx.response.inner.Write(buffer.Bytes())
+	return err
+}
@@ -0,0 +1,68 @@
+package aero_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestServerTiming(t *testing.T) {
+	app := aero.New()
+	app.Config.ServerTiming = true
+
+	app.Use(func(next aero.Handler) aero.Handler {
+		return func(ctx aero.Context) error {
+			ctx.Timing("auth", 2*time.Millisecond, "")
+			return next(ctx)
+		}
+	})
+
+	app.Get("/", func(ctx aero.Context) error {
+		ctx.Timing("db", 1500*time.Microsecond, `Query "users"`)
+		return ctx.Text(helloWorld)
+	})
+
+	app.Get("/large", func(ctx aero.Context) error {
+		return ctx.Text(strings.Repeat(helloWorld, 1000))
+	})
+
+	app.Get("/late", func(ctx aero.Context) error {
+		err := ctx.Text(helloWorld)
+		ctx.Timing("late", time.Millisecond, "")
+		return err
+	})
+
+	app.BindMiddleware()
+
+	response := test(app, "/")
+	timing := response.Header().Get("Server-Timing")
+	assert.True(t, strings.HasPrefix(timing, "router;dur="))
+	assert.Contains(t, timing, `, auth;dur=2.000, db;dur=1.500;desc="Query \"users\"", handler;dur=`)
+
+	response = test(app, "/large")
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Header().Get("Content-Encoding"), "gzip")
+	assert.Contains(t, response.Header().Get("Server-Timing"), ", gzip;dur=")
+	assert.Contains(t, response.Header().Get("Server-Timing"), ", handler;dur=")
+
+	// Entries added after the headers were sent are dropped
+	response = test(app, "/late")
+	assert.NotContains(t, response.Header().Get("Server-Timing"), "late")
+}
+
+func TestServerTimingDisabled(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/", func(ctx aero.Context) error {
+		ctx.Timing("db", time.Millisecond, "")
+		return ctx.Text(helloWorld)
+	})
+
+	response := test(app, "/")
+	assert.Equal(t, response.Code, http.StatusOK)
+	assert.Equal(t, response.Header().Get("Server-Timing"), "")
+}
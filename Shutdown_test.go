@@ -0,0 +1,117 @@
+package aero_test
+
+import (
+	stdContext "context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestApplicationShutdown(t *testing.T) {
+	app := aero.New()
+	app.Config.Ports.HTTP = 4010
+	app.Config.Shutdown.Timeout.Duration = 50 * time.Millisecond
+	started := make(chan struct{}, 2)
+
+	app.Get("/slow", func(ctx aero.Context) error {
+		started <- struct{}{}
+		time.Sleep(300 * time.Millisecond)
+		return ctx.Text(helloWorld)
+	})
+
+	app.Get("/events", func(ctx aero.Context) error {
+		stream := aero.NewEventStream()
+		stream.Final = &aero.Event{Name: "shutdown", Data: "reconnect"}
+		started <- struct{}{}
+		return ctx.EventStream(stream)
+	})
+
+	var serverErrors []error
+	var deadline time.Time
+	ended := false
+
+	app.OnServerError(func(err error) {
+		serverErrors = append(serverErrors, err)
+	})
+
+	app.OnShutdown(func(ctx stdContext.Context) {
+		deadline, _ = ctx.Deadline()
+	})
+
+	app.OnEnd(func() {
+		ended = true
+	})
+
+	app.BindMiddleware()
+	app.ListenAndServe()
+
+	events := make(chan string, 1)
+	url := fmt.Sprintf("http://localhost:%d", app.Config.Ports.HTTP)
+
+	go func() {
+		response, err := http.Get(url + "/events")
+
+		if err != nil {
+			events <- err.Error()
+			return
+		}
+
+		defer response.Body.Close()
+		body, _ := ioutil.ReadAll(response.Body)
+		events <- string(body)
+	}()
+
+	go func() {
+		response, err := http.Get(url + "/slow")
+
+		if err == nil {
+			response.Body.Close()
+		}
+	}()
+
+	<-started
+	<-started
+	start := time.Now()
+	app.Shutdown()
+
+	assert.True(t, time.Since(start) < 250*time.Millisecond)
+	assert.Equal(t, <-events, "event: shutdown\ndata: reconnect\n\n")
+	assert.Equal(t, len(serverErrors), 1)
+	assert.True(t, errors.Is(serverErrors[0], stdContext.DeadlineExceeded))
+	assert.False(t, deadline.IsZero())
+	assert.True(t, ended)
+	assert.True(t, app.IsShuttingDown())
+}
+
+func TestApplicationShutdownDelay(t *testing.T) {
+	app := aero.New()
+	app.Config.Shutdown.Delay.Duration = 50 * time.Millisecond
+	app.ServeHealth("", "/readyz")
+
+	done := make(chan struct{})
+
+	go func() {
+		app.Shutdown()
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	// Readiness fails while the servers are still running
+	response := test(app, "/readyz")
+	assert.Equal(t, response.Code, http.StatusServiceUnavailable)
+
+	select {
+	case <-done:
+		t.Fatal("Shutdown didn't wait for the delay")
+	default:
+	}
+
+	<-done
+}
@@ -0,0 +1,126 @@
+package aero
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// systemdListenFDsStart is the first file descriptor passed by systemd.
+const systemdListenFDsStart = 3
+
+// systemdSocket is a file descriptor passed via socket activation.
+// The file stays open so that the socket can be used again
+// after the listener created from it has been closed.
+type systemdSocket struct {
+	name    string
+	file    *os.File
+	used    bool
+	address string
+}
+
+var (
+	systemdSockets     []*systemdSocket
+	systemdSocketsOnce sync.Once
+	systemdSocketsLock sync.Mutex
+)
+
+// systemdListener returns a listener for a socket passed by systemd.
+// An empty name returns the next socket that hasn't been used yet.
+func systemdListener(name string) (net.Listener, error) {
+	systemdSocketsOnce.Do(loadSystemdSockets)
+
+	systemdSocketsLock.Lock()
+	defer systemdSocketsLock.Unlock()
+
+	if len(systemdSockets) == 0 {
+		return nil, errors.New("No sockets passed by systemd (LISTEN_FDS)")
+	}
+
+	for _, socket := range systemdSockets {
+		if socket.used || (name != "" && socket.name != name) {
+			continue
+		}
+
+		listener, err := net.FileListener(socket.file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// The listener holds its own copy of the file descriptor
+		socket.used = true
+		socket.address = listenerAddress(listener)
+		return listener, nil
+	}
+
+	if name == "" {
+		return nil, errors.New("All sockets passed by systemd are in use")
+	}
+
+	return nil, fmt.Errorf("No socket named '%s' passed by systemd", name)
+}
+
+// releaseSystemdListeners makes the sockets of the closed
+// listeners available again for a later Start in the same process.
+func releaseSystemdListeners(listeners []net.Listener) {
+	systemdSocketsLock.Lock()
+	defer systemdSocketsLock.Unlock()
+
+	for _, listener := range listeners {
+		address := listenerAddress(listener)
+
+		for _, socket := range systemdSockets {
+			if socket.used && socket.address == address {
+				socket.used = false
+				socket.address = ""
+			}
+		}
+	}
+}
+
+// listenerAddress identifies the socket of a listener.
+func listenerAddress(listener net.Listener) string {
+	address := listener.Addr()
+	return address.Network() + "://" + address.String()
+}
+
+// loadSystemdSockets reads the file descriptors passed via the
+// LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES environment variables.
+// The variables are removed so that child processes don't use them.
+func loadSystemdSockets() {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+
+	if err != nil || pid != os.Getpid() {
+		return
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+
+	if err != nil || count <= 0 {
+		return
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	for i := 0; i < count; i++ {
+		name := ""
+
+		if i < len(names) {
+			name = names[i]
+		}
+
+		systemdSockets = append(systemdSockets, &systemdSocket{
+			name: name,
+			file: os.NewFile(uintptr(systemdListenFDsStart+i), name),
+		})
+	}
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+}
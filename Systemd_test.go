@@ -0,0 +1,44 @@
+package aero
+
+import (
+	stdContext "context"
+	"net"
+	"testing"
+
+	"github.com/akyoto/assert"
+)
+
+func TestSystemdListenerReuse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	file, err := listener.(*net.TCPListener).File()
+	assert.Nil(t, err)
+	listener.Close()
+
+	// Simulate a socket passed by systemd
+	systemdSocketsOnce.Do(func() {})
+	systemdSocketsLock.Lock()
+	previous := systemdSockets
+	systemdSockets = []*systemdSocket{{name: "web", file: file}}
+	systemdSocketsLock.Unlock()
+
+	defer func() {
+		systemdSocketsLock.Lock()
+		systemdSockets = previous
+		systemdSocketsLock.Unlock()
+		file.Close()
+	}()
+
+	// The socket can be used again after the app has been stopped
+	for i := 0; i < 2; i++ {
+		app := New()
+		app.Config.Listeners = []ListenerConfiguration{{Network: "systemd", Address: "web"}}
+		assert.Nil(t, app.Start(stdContext.Background()))
+
+		other := New()
+		other.Config.Listeners = app.Config.Listeners
+		assert.NotNil(t, other.Start(stdContext.Background()))
+
+		assert.Nil(t, app.Stop(stdContext.Background()))
+	}
+}
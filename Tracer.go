@@ -0,0 +1,258 @@
+package aero
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sampledFlag is the bit of the W3C trace flags that marks a sampled trace.
+const sampledFlag = 0x01
+
+// Tracer creates spans for distributed tracing.
+// Implementations can export the spans to any tracing backend.
+type Tracer interface {
+	Start(parent SpanContext, name string) Span
+}
+
+// Span is a timed operation within a trace.
+type Span interface {
+	Context() SpanContext
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// SpanContext identifies a span and its trace.
+// The IDs use the lowercase hex encoding of W3C trace context.
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+// IsValid reports whether the span context contains a trace ID and a span ID.
+func (spanContext SpanContext) IsValid() bool {
+	return spanContext.TraceID != "" && spanContext.SpanID != ""
+}
+
+// StartSpan starts a child span of the current span of the request.
+// It returns a span that does nothing if no tracer is configured.
+func (ctx *context) StartSpan(name string) Span {
+	if ctx.app.Tracer == nil {
+		return noopSpan{}
+	}
+
+	return ctx.app.Tracer.Start(ctx.spanContext(), name)
+}
+
+// spanContext returns the context of the current span.
+func (ctx *context) spanContext() SpanContext {
+	if ctx.span == nil {
+		return SpanContext{}
+	}
+
+	return ctx.span.Context()
+}
+
+// startSpan starts a child span and makes it the current span.
+// It returns the new span and the previous one for endSpan.
+func (ctx *context) startSpan(name string) (Span, Span) {
+	parent := ctx.span
+	ctx.span = ctx.app.Tracer.Start(ctx.spanContext(), name)
+	return ctx.span, parent
+}
+
+// endSpan ends the span and restores the previous span.
+func (ctx *context) endSpan(span Span, parent Span) {
+	span.End()
+	ctx.span = parent
+}
+
+// startRequestSpan starts the root span of the request. Its parent
+// is taken from the traceparent header if the request contains one.
+func (app *Application) startRequestSpan(ctx *context) {
+	request := ctx.request.inner
+	parent := SpanContext{}
+	traceID, parentID, flags, ok := parseTraceParent(request.Header.Get(traceParentHeader))
+	traceFlags := uint64(0)
+
+	if ok {
+		traceFlags, _ = strconv.ParseUint(flags, 16, 8)
+
+		parent = SpanContext{
+			TraceID: traceID,
+			SpanID:  parentID,
+			Sampled: traceFlags&sampledFlag != 0,
+		}
+	}
+
+	span := app.Tracer.Start(parent, "aero.request")
+	span.SetAttribute("http.method", request.Method)
+	span.SetAttribute("http.target", request.URL.Path)
+	span.SetAttribute("http.scheme", ctx.request.Scheme())
+	ctx.span = span
+	ctx.rootSpan = span
+
+	spanContext := span.Context()
+
+	if spanContext.IsValid() {
+		// Other flags of the incoming trace are propagated unchanged
+		if spanContext.Sampled {
+			traceFlags |= sampledFlag
+		} else {
+			traceFlags &^= sampledFlag
+		}
+
+		ctx.trace.traceID = spanContext.TraceID
+		ctx.trace.traceFlags = fmt.Sprintf("%02x", traceFlags)
+	}
+}
+
+// traceHandler wraps the handler in a span.
+func traceHandler(handler Handler) Handler {
+	return func(ctx Context) error {
+		internal, ok := ctx.(*context)
+
+		if !ok || internal.app.Tracer == nil {
+			return handler(ctx)
+		}
+
+		span, parent := internal.startSpan("aero.handler")
+		defer internal.endSpan(span, parent)
+		span.SetAttribute("http.route", internal.Route())
+		err := handler(ctx)
+
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		return err
+	}
+}
+
+// endRequestSpan adds the route and the status to the root span and ends it.
+func (ctx *context) endRequestSpan() {
+	ctx.rootSpan.SetAttribute("http.route", ctx.Route())
+	ctx.rootSpan.SetAttribute("http.status_code", ctx.Status())
+	ctx.rootSpan.End()
+	ctx.rootSpan = nil
+	ctx.span = nil
+}
+
+// noopSpan is used when tracing is disabled.
+type noopSpan struct{}
+
+// Context returns an empty span context.
+func (noopSpan) Context() SpanContext { return SpanContext{} }
+
+// SetAttribute does nothing.
+func (noopSpan) SetAttribute(string, interface{}) {}
+
+// RecordError does nothing.
+func (noopSpan) RecordError(error) {}
+
+// End does nothing.
+func (noopSpan) End() {}
+
+// MemoryTracer keeps all finished spans in memory.
+// It is meant for tests and debugging.
+type MemoryTracer struct {
+	mutex sync.Mutex
+	spans []*MemorySpan
+}
+
+// MemorySpan is a span recorded by the MemoryTracer.
+type MemorySpan struct {
+	Name       string
+	TraceID    string
+	SpanID     string
+	ParentID   string
+	Sampled    bool
+	Attributes map[string]interface{}
+	Errors     []error
+	StartTime  time.Time
+	EndTime    time.Time
+
+	tracer *MemoryTracer
+	mutex  sync.Mutex
+}
+
+// NewMemoryTracer creates a new in-memory tracer.
+func NewMemoryTracer() *MemoryTracer {
+	return &MemoryTracer{}
+}
+
+// Start starts a new span. Spans without a valid parent start a new trace.
+func (tracer *MemoryTracer) Start(parent SpanContext, name string) Span {
+	span := &MemorySpan{
+		Name:       name,
+		SpanID:     randomHex(8),
+		Attributes: make(map[string]interface{}),
+		StartTime:  time.Now(),
+		tracer:     tracer,
+	}
+
+	if parent.IsValid() {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+		span.Sampled = parent.Sampled
+	} else {
+		span.TraceID = randomHex(16)
+		span.Sampled = true
+	}
+
+	return span
+}
+
+// Spans returns the finished spans in the order they ended.
+func (tracer *MemoryTracer) Spans() []*MemorySpan {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	spans := make([]*MemorySpan, len(tracer.spans))
+	copy(spans, tracer.spans)
+	return spans
+}
+
+// Reset removes all recorded spans.
+func (tracer *MemoryTracer) Reset() {
+	tracer.mutex.Lock()
+	tracer.spans = nil
+	tracer.mutex.Unlock()
+}
+
+// Context returns the IDs of the span.
+func (span *MemorySpan) Context() SpanContext {
+	return SpanContext{
+		TraceID: span.TraceID,
+		SpanID:  span.SpanID,
+		Sampled: span.Sampled,
+	}
+}
+
+// SetAttribute sets an attribute of the span.
+func (span *MemorySpan) SetAttribute(key string, value interface{}) {
+	span.mutex.Lock()
+	span.Attributes[key] = value
+	span.mutex.Unlock()
+}
+
+// RecordError adds an error to the span.
+func (span *MemorySpan) RecordError(err error) {
+	span.mutex.Lock()
+	span.Errors = append(span.Errors, err)
+	span.mutex.Unlock()
+}
+
+// End finishes the span and records it in the tracer.
+func (span *MemorySpan) End() {
+	span.mutex.Lock()
+	span.EndTime = time.Now()
+	span.mutex.Unlock()
+
+	span.tracer.mutex.Lock()
+	span.tracer.spans = append(span.tracer.spans, span)
+	span.tracer.mutex.Unlock()
+}
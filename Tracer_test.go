@@ -0,0 +1,182 @@
+package aero_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aerogo/aero"
+	"github.com/akyoto/assert"
+)
+
+func TestTracer(t *testing.T) {
+	var received http.Header
+
+	backend := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		received = request.Header
+	}))
+
+	defer backend.Close()
+
+	app := aero.New()
+	tracer := aero.NewMemoryTracer()
+	app.Tracer = tracer
+
+	app.Use(func(next aero.Handler) aero.Handler {
+		return func(ctx aero.Context) error {
+			return next(ctx)
+		}
+	})
+
+	app.Get("/user/:id", func(ctx aero.Context) error {
+		span := ctx.StartSpan("db")
+		span.SetAttribute("db.statement", "SELECT")
+		span.End()
+
+		response, err := aero.NewClient(ctx).Get(backend.URL)
+
+		if err != nil {
+			return err
+		}
+
+		response.Body.Close()
+		return ctx.Text(strings.Repeat(helloWorld, 1000))
+	})
+
+	app.BindMiddleware()
+
+	request := httptest.NewRequest("GET", "/user/42", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, request)
+	assert.Equal(t, response.Code, http.StatusOK)
+
+	spans := map[string]*aero.MemorySpan{}
+
+	for _, span := range tracer.Spans() {
+		spans[span.Name] = span
+		assert.Equal(t, span.TraceID, "4bf92f3577b34da6a3ce929d0e0e4736")
+		assert.True(t, span.Sampled)
+		assert.False(t, span.EndTime.Before(span.StartTime))
+	}
+
+	assert.Equal(t, len(spans), 6)
+
+	root := spans["aero.request"]
+	assert.Equal(t, root.ParentID, "00f067aa0ba902b7")
+	assert.Equal(t, root.Attributes["http.method"], "GET")
+	assert.Equal(t, root.Attributes["http.target"], "/user/42")
+	assert.Equal(t, root.Attributes["http.route"], "/user/:id")
+	assert.Equal(t, root.Attributes["http.status_code"], http.StatusOK)
+
+	assert.Equal(t, spans["aero.router"].ParentID, root.SpanID)
+	assert.Equal(t, spans["aero.router"].Attributes["http.route"], "/user/:id")
+	assert.Equal(t, spans["aero.middleware"].ParentID, root.SpanID)
+	assert.Equal(t, spans["aero.handler"].ParentID, spans["aero.middleware"].SpanID)
+	assert.Equal(t, spans["aero.handler"].Attributes["http.route"], "/user/:id")
+	assert.Equal(t, spans["db"].ParentID, spans["aero.handler"].SpanID)
+	assert.Equal(t, spans["db"].Attributes["db.statement"], "SELECT")
+	assert.Equal(t, spans["aero.gzip"].ParentID, spans["aero.handler"].SpanID)
+
+	// Outgoing requests continue the trace with the current span as parent
+	assert.Equal(t, received.Get("traceparent"), "00-4bf92f3577b34da6a3ce929d0e0e4736-"+spans["aero.handler"].SpanID+"-01")
+}
+
+func TestTracerFlags(t *testing.T) {
+	var received string
+
+	backend := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		received = request.Header.Get("traceparent")
+	}))
+
+	defer backend.Close()
+
+	app := aero.New()
+	tracer := aero.NewMemoryTracer()
+	app.Tracer = tracer
+
+	app.Get("/", func(ctx aero.Context) error {
+		response, err := aero.NewClient(ctx).Get(backend.URL)
+
+		if err != nil {
+			return err
+		}
+
+		response.Body.Close()
+		return nil
+	})
+
+	// The sampled bit is read from the hex value and other bits are kept
+	flags := map[string]bool{
+		"00": false,
+		"01": true,
+		"0a": false,
+		"0b": true,
+		"ff": true,
+	}
+
+	for flag, sampled := range flags {
+		tracer.Reset()
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-"+flag)
+		app.ServeHTTP(httptest.NewRecorder(), request)
+
+		spans := tracer.Spans()
+		root := spans[len(spans)-1]
+		assert.Equal(t, root.Sampled, sampled)
+		assert.True(t, strings.HasSuffix(received, "-"+flag))
+	}
+}
+
+func TestTracerNewTrace(t *testing.T) {
+	app := aero.New()
+	tracer := aero.NewMemoryTracer()
+	app.Tracer = tracer
+	app.Use(aero.RequestID())
+
+	app.Get("/", func(ctx aero.Context) error {
+		return ctx.Text(ctx.RequestID())
+	})
+
+	app.Get("/panic", func(ctx aero.Context) error {
+		panic("oops")
+	})
+
+	app.OnError(func(ctx aero.Context, err error) {})
+	app.BindMiddleware()
+
+	response := test(app, "/")
+	spans := tracer.Spans()
+	root := spans[len(spans)-1]
+	assert.Equal(t, root.Name, "aero.request")
+	assert.Equal(t, root.ParentID, "")
+	assert.Equal(t, len(root.TraceID), 32)
+
+	// The request ID is the trace ID
+	assert.Equal(t, response.Body.String(), root.TraceID)
+
+	tracer.Reset()
+	test(app, "/panic")
+	spans = tracer.Spans()
+	root = spans[len(spans)-1]
+	assert.Equal(t, root.Name, "aero.request")
+	assert.Equal(t, root.Attributes["http.status_code"], http.StatusInternalServerError)
+	assert.Equal(t, len(root.Errors), 1)
+}
+
+func TestTracerDisabled(t *testing.T) {
+	app := aero.New()
+
+	app.Get("/", func(ctx aero.Context) error {
+		span := ctx.StartSpan("noop")
+		span.SetAttribute("key", "value")
+		span.End()
+		assert.False(t, span.Context().IsValid())
+		return ctx.Text(helloWorld)
+	})
+
+	response := test(app, "/")
+	assert.Equal(t, response.Code, http.StatusOK)
+}
@@ -0,0 +1,56 @@
+package aero
+
+// maxPooledValues is the capacity above which the value store
+// of a context is released instead of being reused.
+const maxPooledValues = 32
+
+// valueStore holds the request-scoped values of a context.
+// Most requests only store a handful of values, therefore
+// a slice is faster than a map here.
+type valueStore struct {
+	entries []valueEntry
+}
+
+// valueEntry is a single key/value pair in the store.
+type valueEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+// get returns the value for the key or nil if it doesn't exist.
+func (store *valueStore) get(key interface{}) (interface{}, bool) {
+	for i := range store.entries {
+		if store.entries[i].key == key {
+			return store.entries[i].value, true
+		}
+	}
+
+	return nil, false
+}
+
+// set adds or replaces the value for the key.
+func (store *valueStore) set(key interface{}, value interface{}) {
+	for i := range store.entries {
+		if store.entries[i].key == key {
+			store.entries[i].value = value
+			return
+		}
+	}
+
+	store.entries = append(store.entries, valueEntry{key: key, value: value})
+}
+
+// reset removes all values and clears the references
+// so that pooled contexts don't keep them alive.
+func (store *valueStore) reset() {
+	if cap(store.entries) > maxPooledValues {
+		store.entries = nil
+		return
+	}
+
+	for i := range store.entries {
+		store.entries[i] = valueEntry{}
+	}
+
+	store.entries = store.entries[:0]
+}
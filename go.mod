@@ -1,6 +1,6 @@
 module github.com/aerogo/aero
 
-go 1.12
+go 1.13
 
 require (
 	github.com/aerogo/csp v0.1.9
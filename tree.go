@@ -26,7 +26,7 @@ const (
 )
 
 // dataType specifies which type of data we are going to save for each node.
-type dataType = Handler
+type dataType = *route
 
 // tree represents a radix tree.
 type tree struct {
@@ -303,7 +303,7 @@ func (node *tree) end(path string, data dataType, i int, offset int) (*tree, int
 	return node, offset, controlStop
 }
 
-// find finds the data for the given path and assigns it to ctx.handler, if available.
+// find finds the data for the given path and assigns it to ctx.route, if available.
 func (node *tree) find(path string, ctx *context) {
 	var (
 		i                  int
@@ -319,7 +319,7 @@ func (node *tree) find(path string, ctx *context) {
 		case parameter:
 			if i == len(path) {
 				ctx.addParameter(node.prefix, path[offset:i])
-				ctx.handler = node.data
+				ctx.route = node.data
 				return
 			}
 
@@ -336,13 +336,13 @@ func (node *tree) find(path string, ctx *context) {
 				// node: /blog|
 				// path: /blog|
 				if i-offset == len(node.prefix) {
-					ctx.handler = node.data
+					ctx.route = node.data
 					return
 				}
 
 				// node: /blog|feed
 				// path: /blog|
-				ctx.handler = nil
+				ctx.route = nil
 				return
 			}
 
@@ -375,11 +375,11 @@ func (node *tree) find(path string, ctx *context) {
 				// path: /|image.png
 				if node.wildcard != nil {
 					ctx.addParameter(node.wildcard.prefix, path[i:])
-					ctx.handler = node.wildcard.data
+					ctx.route = node.wildcard.data
 					return
 				}
 
-				ctx.handler = nil
+				ctx.route = nil
 				return
 			}
 
@@ -389,11 +389,11 @@ func (node *tree) find(path string, ctx *context) {
 			if path[i] != node.prefix[i-offset] {
 				if lastWildcard != nil {
 					ctx.addParameter(lastWildcard.prefix, path[lastWildcardOffset:])
-					ctx.handler = lastWildcard.data
+					ctx.route = lastWildcard.data
 					return
 				}
 
-				ctx.handler = nil
+				ctx.route = nil
 				return
 			}
 		}